@@ -10,9 +10,15 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/joshdk/buildversion"
 	"github.com/spf13/cobra"
@@ -23,14 +29,23 @@ import (
 // Command returns a complete command line handler for modfmt.
 func Command() *cobra.Command { //nolint:cyclop,funlen
 	cmd := &cobra.Command{
-		Use:     "modfmt [directory|file]",
+		Use:     "modfmt [directory|file|-]",
 		Long:    "modfmt - formatter for go.mod and go.work files",
 		Version: "-",
 
+		// Arbitrary arguments are allowed, since paths are given alongside
+		// subcommands.
+		Args: cobra.ArbitraryArgs,
+
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
 
+	// Add subcommands.
+	cmd.AddCommand(lintCommand())
+	cmd.AddCommand(mergeDriverCommand())
+	cmd.AddCommand(diffCommand())
+
 	// Set a custom list of examples.
 	cmd.Example = strings.TrimRight(exampleText, "\n")
 
@@ -46,6 +61,12 @@ func Command() *cobra.Command { //nolint:cyclop,funlen
 		false,
 		"exit with code 1 if any files were unformatted")
 
+	// Define --diff/-d flag.
+	diff := cmd.Flags().BoolP(
+		"diff", "d",
+		false,
+		"display diffs instead of rewriting files")
+
 	// Define --list/-l flag.
 	list := cmd.Flags().BoolP(
 		"list", "l",
@@ -58,64 +79,344 @@ func Command() *cobra.Command { //nolint:cyclop,funlen
 		false,
 		"write result to (source) file instead of stdout")
 
-	cmd.RunE = func(_ *cobra.Command, args []string) error {
-		// If no arguments are given, default to recursively searching through
-		// the current working directory.
-		if len(args) == 0 {
+	// Define --backup flag.
+	backup := cmd.Flags().String(
+		"backup",
+		"",
+		"when writing, keep the original contents in a file with this suffix")
+
+	// Define --stdin-filename flag.
+	stdinFilename := cmd.Flags().String(
+		"stdin-filename",
+		"go.mod",
+		"file name to use when formatting standard input")
+
+	// Define --config flag.
+	configFile := cmd.Flags().String(
+		"config",
+		"",
+		"use the given configuration file instead of searching for one")
+
+	// Define --print-config flag.
+	printConfig := cmd.Flags().Bool(
+		"print-config",
+		false,
+		"print the effective configuration for each file and exit")
+
+	// Define --order flag.
+	cmd.Flags().StringSlice(
+		"order",
+		nil,
+		"order in which sections are written")
+
+	// Define --merge-indirect flag.
+	cmd.Flags().Bool(
+		"merge-indirect",
+		false,
+		"merge indirect dependencies into the same block as direct dependencies")
+
+	// Define --merge-local flag.
+	cmd.Flags().Bool(
+		"merge-local",
+		false,
+		"merge local replacements into the same block as module replacements")
+
+	// Define --collapse flag.
+	cmd.Flags().Bool(
+		"collapse",
+		false,
+		"write single-entry blocks as single-line directives")
+
+	// Define --keep-groups flag.
+	cmd.Flags().Bool(
+		"keep-groups",
+		false,
+		"keep blank-line separated groups of directives within blocks")
+
+	// Define --comments flag.
+	cmd.Flags().String(
+		"comments",
+		string(modfmt.CommentsNormalize),
+		`how comments are formatted, either "normalize" or "preserve"`)
+
+	// Define --dedupe flag.
+	cmd.Flags().Bool(
+		"dedupe",
+		false,
+		"merge duplicate require, exclude, and tool directives")
+
+	// Define --on-conflict flag.
+	cmd.Flags().String(
+		"on-conflict",
+		string(modfmt.ConflictHighest),
+		`how conflicting versions of the same required module are resolved with --dedupe, either "highest" or "error"`)
+
+	// Define --canonical flag.
+	cmd.Flags().Bool(
+		"canonical",
+		false,
+		"fix non-canonical module versions, and report invalid module paths")
+
+	// Define --resolve-conflicts flag.
+	cmd.Flags().Bool(
+		"resolve-conflicts",
+		false,
+		"merge both sides of any git conflicts in go.mod files")
+
+	// Define --format flag.
+	output := cmd.Flags().String(
+		"format",
+		outputText,
+		`output format, either "text", "json", or "sarif"`)
+
+	// Define --jobs/-j flag.
+	jobs := cmd.Flags().IntP(
+		"jobs", "j",
+		runtime.GOMAXPROCS(0),
+		"number of files to format concurrently")
+
+	// Define --keep-going flag.
+	keepGoing := cmd.Flags().Bool(
+		"keep-going",
+		true,
+		"keep formatting remaining files after an error")
+
+	// Define --exclude flag.
+	exclude := cmd.Flags().StringArray(
+		"exclude",
+		nil,
+		"skip paths matching the given .gitignore style pattern when searching directories")
+
+	// Define --include flag.
+	include := cmd.Flags().StringArray(
+		"include",
+		nil,
+		"only find files matching the given .gitignore style pattern when searching directories")
+
+	// Define --no-ignore flag.
+	noIgnore := cmd.Flags().Bool(
+		"no-ignore",
+		false,
+		"do not skip paths ignored by .gitignore or .git/info/exclude files")
+
+	// Define --changed-since flag.
+	changedSince := cmd.Flags().String(
+		"changed-since",
+		"",
+		"only format files which differ from the given git revision")
+
+	// Define --staged flag.
+	staged := cmd.Flags().Bool(
+		"staged",
+		false,
+		"only format files which differ between the git index and HEAD (or --changed-since)")
+
+	// Define --git-index flag.
+	gitIndex := cmd.Flags().Bool(
+		"git-index",
+		false,
+		"format the contents staged in the git index instead of the working tree (implies --staged)")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		switch *output {
+		case outputText, outputJSON, outputSARIF:
+		default:
+			return fmt.Errorf("unknown output format %q", *output)
+		}
+
+		if *jobs < 1 {
+			return errors.New("--jobs must be at least 1")
+		}
+
+		// Only staged files can be read from the git index.
+		if *gitIndex {
+			*staged = true
+		}
+
+		changedMode := *changedSince != "" || *staged
+
+		// If no arguments are given, default to searching through the current
+		// working directory. Only changed files are formatted when comparing
+		// against git, so the entire tree is searched instead.
+		switch {
+		case len(args) == 0 && changedMode:
+			args = []string{"./..."}
+		case len(args) == 0:
 			args = []string{"."}
 		}
 
-		// Search for go.mod and go.work files.
-		filenames, err := discover(args)
+		// Search for go.mod and go.work files. Files are sorted, so that
+		// output is ordered consistently, and any duplicates are dropped.
+		filter, err := newPathFilter(*exclude, *include, *noIgnore)
 		if err != nil {
 			return err
 		}
 
-		var unformatted bool
+		filenames, discoverErr := discover(args, filter)
+		if discoverErr != nil && !*keepGoing {
+			return &ExitError{Code: ExitErrors, Err: discoverErr}
+		}
+
+		slices.Sort(filenames)
+		filenames = slices.Compact(filenames)
 
-		for _, filename := range filenames {
-			// Read the original file.
-			original, err := os.ReadFile(filename)
+		if changedMode {
+			// If comparing against git, then drop any files which have not
+			// changed.
+			changed, err := changedFiles(*changedSince, *staged)
 			if err != nil {
 				return err
 			}
 
-			// Format the file.
-			formatted, err := modfmt.Format(filename, original)
-			if err != nil {
+			filenames = slices.DeleteFunc(filenames, func(filename string) bool {
+				if filename == "-" {
+					return false
+				}
+
+				name, err := filepath.Abs(filename)
+
+				return err != nil || !changed[name]
+			})
+		}
+
+		// Validate any options given as flags up front, rather than failing
+		// every file individually.
+		var flagConfig config
+		if err := flagConfig.override(cmd.Flags()); err != nil {
+			return err
+		}
+
+		if err := flagConfig.options().Validate(); err != nil {
+			return err
+		}
+
+		resolver := &configResolver{
+			explicit: *configFile,
+			flags:    cmd.Flags(),
+			cache:    make(map[string]config),
+		}
+
+		if *printConfig {
+			// If print config mode was requested, then print the effective
+			// configuration for each file instead of formatting.
+			for _, filename := range filenames {
+				if filename == "-" {
+					filename = *stdinFilename
+				}
+
+				cfg, source, err := resolver.resolve(filename)
+				if err != nil {
+					return err
+				}
+
+				if err := printConfigFor(cmd.OutOrStdout(), filename, source, cfg); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		// Standard input cannot be written back to, or read from the git
+		// index.
+		switch {
+		case *write && slices.Contains(filenames, "-"):
+			return errors.New("cannot use --write with standard input")
+		case *gitIndex && slices.Contains(filenames, "-"):
+			return errors.New("cannot use --git-index with standard input")
+		}
+
+		// Format all files concurrently. Results are returned in the same
+		// order as the given file names.
+		results := formatFiles(filenames, *jobs, *keepGoing, func(filename string) result {
+			if *gitIndex {
+				return formatIndexFile(filename, resolver, *write, *backup)
+			}
+
+			return formatFile(cmd.InOrStdin(), filename, *stdinFilename, resolver, *write, *backup)
+		})
+
+		// Files that were never formatted, because an earlier file failed and
+		// --keep-going=false was given, are dropped from the output.
+		skipped := len(results)
+		results = slices.DeleteFunc(results, func(res result) bool {
+			return res.skipped
+		})
+		skipped -= len(results)
+
+		switch *output {
+		case outputJSON:
+			if err := reportJSON(cmd.OutOrStdout(), results); err != nil {
 				return err
 			}
+		case outputSARIF:
+			if err := reportSARIF(cmd.OutOrStdout(), results); err != nil {
+				return err
+			}
+		default:
+			for _, res := range results {
+				printResult(cmd.OutOrStdout(), res, *list, *diff, *write)
+			}
+		}
 
-			// Did formatting change the file or was it already formatted?
-			if bytes.Equal(original, formatted) {
-				continue
+		var (
+			failed       int
+			unformatted  int
+			noncanonical int
+		)
+
+		for _, res := range results {
+			switch {
+			case res.err != nil:
+				failed++
+			case res.unformatted():
+				unformatted++
 			}
 
-			unformatted = true
+			if len(res.diagnostics) > 0 {
+				noncanonical++
+			}
 
-			if *write {
-				// If write mode was requested, then silently update the
-				// original file.
-				if err := os.WriteFile(filename, formatted, 0o644); err != nil {
-					return err
+			// Diagnostics are already included in structured output.
+			if *output == outputText {
+				for _, diagnostic := range res.diagnostics {
+					fmt.Fprintln(cmd.ErrOrStderr(), "modfmt:", diagnostic)
 				}
 			}
+		}
 
-			if *list {
-				// If list mode was requested, then list files.
-				fmt.Println(filename)
-			} else if !*write {
-				// Otherwise if write mode was not requested, then print
-				// filename header and formatted body.
-				fmt.Printf("--- %s ---\n", filename)
-				fmt.Print(string(formatted))
+		if failed > 0 || discoverErr != nil {
+			// Print every error that occurred, followed by a summary. Errors
+			// are printed even with structured output, as errors from
+			// searching for files are not otherwise included.
+			for _, err := range unjoin(discoverErr) {
+				fmt.Fprintln(cmd.ErrOrStderr(), "modfmt:", err)
 			}
+
+			for _, res := range results {
+				if res.err != nil {
+					printError(cmd.ErrOrStderr(), res.err, res.original)
+				}
+			}
+
+			return &ExitError{Code: ExitErrors, Err: summarize("formatted", len(filenames), failed, skipped, discoverErr)}
 		}
 
-		if *check && unformatted {
+		switch {
+		case *check && unformatted > 0:
 			// If check mode was requested and any files were unformatted, then
 			// exit with an error.
-			return errors.New("some files were unformatted")
+			return &ExitError{
+				Code: ExitUnformatted,
+				Err:  fmt.Errorf("%d of %d files were unformatted", unformatted, len(filenames)),
+			}
+		case *check && noncanonical > 0:
+			// Likewise if any files had non-canonical versions or invalid
+			// module paths.
+			return &ExitError{
+				Code: ExitUnformatted,
+				Err:  fmt.Errorf("%d of %d files had diagnostics", noncanonical, len(filenames)),
+			}
 		}
 
 		return nil
@@ -124,55 +425,203 @@ func Command() *cobra.Command { //nolint:cyclop,funlen
 	return cmd
 }
 
+// formatFiles calls the given function for each of the given file names,
+// using up to the given number of concurrent workers. Returns the results in
+// the same order as the given file names. If keepGoing is false, then any files
+// not yet started after the first error are marked as skipped.
+func formatFiles(filenames []string, jobs int, keepGoing bool, fn func(string) result) []result {
+	results := make([]result, len(filenames))
+	indices := make(chan int)
+
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Bool
+	)
+
+	for range min(jobs, len(filenames)) {
+		wg.Go(func() {
+			for index := range indices {
+				if !keepGoing && failed.Load() {
+					results[index] = result{filename: filenames[index], skipped: true}
+
+					continue
+				}
+
+				results[index] = fn(filenames[index])
+				if results[index].err != nil {
+					failed.Store(true)
+				}
+			}
+		})
+	}
+
+	for index := range filenames {
+		indices <- index
+	}
+
+	close(indices)
+	wg.Wait()
+
+	return results
+}
+
+// result holds the outcome of formatting a single file.
+type result struct {
+	// filename is the name of the file that was formatted.
+	filename string
+
+	// stdin is true if the file was read from standard input.
+	stdin bool
+
+	// original is the original contents of the file.
+	original []byte
+
+	// formatted is the formatted contents of the file.
+	formatted []byte
+
+	// err is any error that occurred while reading, formatting, or writing
+	// the file.
+	err error
+
+	// skipped is true if the file was never formatted, due to an error with
+	// a different file.
+	skipped bool
+
+	// diagnostics are any non-canonical versions or invalid module paths
+	// found in the original file. Only populated if canonical versions were
+	// requested.
+	diagnostics []modfmt.Diagnostic
+}
+
+// unformatted reports if formatting changed the file.
+func (r result) unformatted() bool {
+	return r.err == nil && !bytes.Equal(r.original, r.formatted)
+}
+
+// checkFile reports any non-canonical versions or invalid module paths in the
+// given file. A file with git conflicts cannot be parsed as-is, so if conflicts
+// are being resolved, then the formatted contents are checked instead.
+func checkFile(filename string, original, formatted []byte, cfg config) ([]modfmt.Diagnostic, error) {
+	diagnostics, err := modfmt.Check(filename, original)
+	if err != nil && cfg.ResolveConflicts {
+		return modfmt.Check(filename, formatted)
+	}
+
+	return diagnostics, err
+}
+
+// formatFile reads, formats, and optionally writes the given file. If the
+// file name is `-` then standard input is read instead, and the given standard
+// input file name is used in its place. If a backup suffix is given, then the
+// original contents are kept when writing.
+func formatFile(stdin io.Reader, filename, stdinFilename string, resolver *configResolver, write bool, backup string) result { //nolint:lll
+	res := result{filename: filename}
+
+	// Read the original file.
+	if filename == "-" {
+		res.filename = stdinFilename
+		res.stdin = true
+		res.original, res.err = io.ReadAll(stdin)
+	} else {
+		res.original, res.err = os.ReadFile(filename)
+	}
+
+	if res.err != nil {
+		return res
+	}
+
+	// Resolve the configuration for the file.
+	cfg, _, err := resolver.resolve(res.filename)
+	if err != nil {
+		res.err = fmt.Errorf("%s: %w", res.filename, err)
+
+		return res
+	}
+
+	// Format the file.
+	if res.formatted, res.err = modfmt.FormatWithOptions(res.filename, res.original, cfg.options()); res.err != nil {
+		return res
+	}
+
+	if cfg.Canonical {
+		if res.diagnostics, res.err = checkFile(res.filename, res.original, res.formatted, cfg); res.err != nil {
+			return res
+		}
+	}
+
+	if write && res.unformatted() {
+		// If write mode was requested, then silently update the original
+		// file.
+		res.err = writeFile(filename, res.formatted, res.original, backup)
+	}
+
+	return res
+}
+
+// printResult writes the given result in a human-readable form.
+func printResult(w io.Writer, res result, list, diff, write bool) {
+	switch {
+	case res.stdin && !list && !diff:
+		// Standard input is always written to standard output, regardless of
+		// if it changed.
+		w.Write(res.formatted) //nolint:errcheck
+	case !res.unformatted():
+		// Nothing to print if the file was already formatted.
+	case list:
+		// If list mode was requested, then list files.
+		fmt.Fprintln(w, res.filename)
+	case diff:
+		// If diff mode was requested, then print a unified diff between the
+		// original and formatted file.
+		fmt.Fprint(w, unifiedDiff(res.filename, res.original, res.formatted))
+	case !write:
+		// Otherwise if write mode was not requested, then print filename
+		// header and formatted body.
+		fmt.Fprintf(w, "--- %s ---\n", res.filename)
+		w.Write(res.formatted) //nolint:errcheck
+	}
+}
+
 // discover returns a list of `go.mod` and `go.work` file paths based on the
 // given specs. Each spec can be one of the following:
 //   - If an explicit file name is given, it will be returned verbatim.
+//   - If `-` is given, it will be returned verbatim to signify standard input.
 //   - If a directory name is given, any directly contained `go.mod` or
 //     `go.work` files are returned.
 //   - If the given spec ends with `/...` then it is treated as a directory and
-//     walked in search of any `go.mod` or `go.work` files.
-func discover(specs []string) ([]string, error) { //nolint:cyclop
-	var results []string
+//     walked in search of any `go.mod` or `go.work` files, skipping any paths
+//     according to the given filter.
+//
+// Searching continues past any errors, which are returned joined together
+// alongside every file that could be found.
+func discover(specs []string, filter pathFilter) ([]string, error) {
+	var (
+		results []string
+		errs    []error
+	)
 
 	for _, spec := range specs {
-		if directory, ok := strings.CutSuffix(spec, "/..."); ok {
-			// If the spec ends with `/...` then walk through the directory.
-			if err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
-				switch {
-				case err != nil:
-					// There was an actual error.
-					return err
+		if spec == "-" {
+			// Standard input was requested.
+			results = append(results, spec)
 
-				case info.IsDir():
-					switch info.Name() {
-					case ".git":
-						// Ignore `.git/` directory.
-						return filepath.SkipDir
-					case "vendor":
-						// Ignore `vendor/` directory.
-						return filepath.SkipDir
-					}
-
-				case info.Name() == "go.mod":
-					// Found a `go.mod` file!
-					results = append(results, path)
-
-				case info.Name() == "go.work":
-					// Found a `go.work` file!
-					results = append(results, path)
-				}
+			continue
+		}
 
-				return nil
-			}); err != nil {
-				return nil, err
-			}
+		if directory, ok := strings.CutSuffix(spec, "/..."); ok {
+			// If the spec ends with `/...` then walk through the directory.
+			found, err := walk(directory, filter)
+			results = append(results, found...)
+			errs = append(errs, unjoin(err)...)
 
 			continue
 		}
 
 		stat, err := os.Stat(spec)
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
+
+			continue
 		}
 
 		// A file was named explicitly.
@@ -195,5 +644,94 @@ func discover(specs []string) ([]string, error) { //nolint:cyclop
 		}
 	}
 
-	return results, nil
+	return results, errors.Join(errs...)
+}
+
+// walk searches through the given directory for any `go.mod` or `go.work`
+// files. The `.git/` and `vendor/` directories are always skipped, along with
+// any paths skipped by the given filter.
+func walk(directory string, filter pathFilter) ([]string, error) { //nolint:cyclop
+	root, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ignore files only apply within a git repository.
+	repository := findRepository(root)
+	if repository == "" {
+		filter.noIgnore = true
+	}
+
+	rules, err := filter.rules(repository, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		results []string
+		errs    []error
+	)
+
+	if err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// There was an actual error. Record it, and keep walking.
+			errs = append(errs, err)
+
+			return nil
+		}
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+
+		skipped := rules.ignored(abs, entry.IsDir()) || filter.exclude.matched(abs, entry.IsDir())
+
+		switch {
+		case path == directory:
+			// Never skip the directory being walked, but load any ignore
+			// rules for it.
+			if !filter.noIgnore {
+				rules, err = rules.load(abs, filepath.Join(abs, ".gitignore"))
+			}
+
+			return err
+
+		case entry.IsDir() && (entry.Name() == ".git" || entry.Name() == "vendor"):
+			// Ignore `.git/` and `vendor/` directories.
+			return filepath.SkipDir
+
+		case entry.IsDir() && skipped:
+			// Ignore excluded directories.
+			return filepath.SkipDir
+
+		case entry.IsDir():
+			// Load any ignore rules for this directory.
+			if !filter.noIgnore {
+				if rules, err = rules.load(abs, filepath.Join(abs, ".gitignore")); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+		case skipped:
+			// Ignore excluded files.
+
+		case len(filter.include) > 0 && !filter.include.matched(abs, false):
+			// Ignore files which were not included.
+
+		case entry.Name() == "go.mod":
+			// Found a `go.mod` file!
+			results = append(results, path)
+
+		case entry.Name() == "go.work":
+			// Found a `go.work` file!
+			results = append(results, path)
+		}
+
+		return nil
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	return results, errors.Join(errs...)
 }
@@ -0,0 +1,175 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFormatFiles(t *testing.T) {
+	t.Parallel()
+
+	filenames := []string{"a", "b", "fail", "c", "d", "e"}
+
+	tests := []struct {
+		name      string
+		keepGoing bool
+		skipped   []string
+	}{
+		{
+			name:      "keep going",
+			keepGoing: true,
+		},
+		{
+			name:    "stop",
+			skipped: []string{"c", "d", "e"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				// started is done once the first files have each been
+				// started by a separate worker.
+				started sync.WaitGroup
+
+				// failed is closed once the failing file has been formatted.
+				failed = make(chan struct{})
+
+				mu     sync.Mutex
+				called []string
+			)
+
+			started.Add(3)
+
+			results := formatFiles(filenames, 3, test.keepGoing, func(filename string) result {
+				mu.Lock()
+				called = append(called, filename)
+				mu.Unlock()
+
+				switch filename {
+				case "a", "b":
+					// Hold these workers until after the failure has been
+					// recorded, so that every later file is given to the
+					// worker which failed.
+					started.Done()
+					<-failed
+					time.Sleep(10 * time.Millisecond)
+				case "fail":
+					started.Done()
+					started.Wait()
+					close(failed)
+
+					return result{filename: filename, err: errors.New("failed")}
+				}
+
+				return result{filename: filename}
+			})
+
+			if len(results) != len(filenames) {
+				t.Fatalf("expected %d results, actual %d", len(filenames), len(results))
+			}
+
+			for index, res := range results {
+				// Results are in the same order as the given file names.
+				if res.filename != filenames[index] {
+					t.Fatalf("expected result %d for %q, actual %q", index, filenames[index], res.filename)
+				}
+
+				if (res.err != nil) != (res.filename == "fail") {
+					t.Fatalf("%s: unexpected error %v", res.filename, res.err)
+				}
+
+				skipped := slices.Contains(test.skipped, res.filename)
+
+				if res.skipped != skipped {
+					t.Fatalf("%s: expected skipped %t, actual %t", res.filename, skipped, res.skipped)
+				}
+
+				// Skipped files are never formatted.
+				if slices.Contains(called, res.filename) == skipped {
+					t.Fatalf("%s: expected formatted %t", res.filename, !skipped)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatFileCanonical(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		original string
+		expected []string
+	}{
+		{
+			name:     "canonical",
+			args:     []string{"--canonical"},
+			original: "module example.com/foo/bar\n\nrequire (\n\texample.com/a/a v1.0\n\texample.com/B!/b v1.0.0\n)\n",
+			expected: []string{
+				`go.mod:4:18: version "v1.0" is not canonical, should be "v1.0.0"`,
+				`go.mod:5:2: malformed module path "example.com/B!/b": invalid char '!'`,
+			},
+		},
+		{
+			// Files with git conflicts are checked once the conflicts have
+			// been resolved.
+			name:     "resolve conflicts",
+			args:     []string{"--canonical", "--resolve-conflicts"},
+			original: "module example.com/foo/bar\n\nrequire (\n<<<<<<< HEAD\n\texample.com/a/a v1.0.0\n=======\n\texample.com/a/a v1.2.0\n>>>>>>> other\n\texample.com/B!/b v1.0.0\n)\n", //nolint:lll
+			expected: []string{
+				`go.mod:4:2: malformed module path "example.com/B!/b": invalid char '!'`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.Join(t.TempDir(), "go.mod")
+
+			if err := os.WriteFile(filename, []byte(test.original), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := Command()
+			if err := cmd.ParseFlags(test.args); err != nil {
+				t.Fatal(err)
+			}
+
+			resolver := &configResolver{
+				flags: cmd.Flags(),
+				cache: make(map[string]config),
+			}
+
+			res := formatFile(nil, filename, "", resolver, false, "")
+			if res.err != nil {
+				t.Fatal(res.err)
+			}
+
+			actual := make([]string, 0, len(res.diagnostics))
+			for _, diagnostic := range res.diagnostics {
+				actual = append(actual, strings.TrimPrefix(diagnostic.String(), filepath.Dir(filename)+string(filepath.Separator)))
+			}
+
+			if !slices.Equal(actual, test.expected) {
+				t.Fatalf("expected:\n%s\nactual:\n%s", strings.Join(test.expected, "\n"), strings.Join(actual, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// jsonChanges is the top-level structure of the JSON output format for
+// `modfmt diff`.
+type jsonChanges struct {
+	Changes []jsonChange `json:"changes"`
+}
+
+// jsonChange describes a single semantic change between two files.
+type jsonChange struct {
+	Verb string `json:"verb"`
+	Path string `json:"path,omitempty"`
+	Kind string `json:"kind"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// diffCommand returns the command line handler for `modfmt diff`.
+func diffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff old new",
+		Short: "Show the semantic changes between two go.mod or go.work files",
+		Long: "Show the semantic changes between two go.mod or go.work files.\n\n" +
+			"Reports added, removed, upgraded, and downgraded requirements, changed replacements, go and toolchain " +
+			"version changes, new retractions, and any other changed directives. Differences in formatting, comments, " +
+			"and the order of directives are ignored.",
+		Args: cobra.ExactArgs(2), //nolint:mnd
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	// Define --format flag.
+	output := cmd.Flags().String(
+		"format",
+		outputText,
+		`output format, either "text" or "json"`)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		switch *output {
+		case outputText, outputJSON:
+		default:
+			return fmt.Errorf("unknown output format %q", *output)
+		}
+
+		oldFile, newFile := args[0], args[1]
+
+		oldData, err := os.ReadFile(oldFile)
+		if err != nil {
+			return err
+		}
+
+		newData, err := os.ReadFile(newFile)
+		if err != nil {
+			return err
+		}
+
+		changes, err := modfmt.Compare(oldFile, oldData, newFile, newData)
+		if err != nil {
+			var parseErr *modfmt.ParseError
+			if !errors.As(err, &parseErr) {
+				return err
+			}
+
+			source := newData
+			if parseErr.Filename == oldFile {
+				source = oldData
+			}
+
+			printError(cmd.ErrOrStderr(), err, source)
+
+			return &ExitError{Code: ExitErrors, Err: fmt.Errorf("%s: could not be parsed", parseErr.Filename)}
+		}
+
+		if *output == outputJSON {
+			return reportChanges(cmd.OutOrStdout(), changes)
+		}
+
+		for _, change := range changes {
+			fmt.Fprintln(cmd.OutOrStdout(), change)
+		}
+
+		return nil
+	}
+
+	return cmd
+}
+
+// reportChanges writes the given changes to the given writer in the JSON
+// output format.
+func reportChanges(w io.Writer, changes []modfmt.Change) error {
+	report := jsonChanges{Changes: make([]jsonChange, 0, len(changes))}
+
+	for _, change := range changes {
+		report.Changes = append(report.Changes, jsonChange{
+			Verb: change.Verb,
+			Path: change.Path,
+			Kind: string(change.Kind),
+			Old:  change.Old,
+			New:  change.New,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
@@ -0,0 +1,375 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+	"github.com/joshdk/modfmt/pkg/modfmt/lint"
+)
+
+// configNames are the names of configuration files, in order of precedence.
+var configNames = []string{".modfmt.yaml", ".modfmt.yml", ".modfmt.toml"}
+
+// config represents the settings contained in a `.modfmt.yaml` or
+// `.modfmt.toml` configuration file.
+type config struct {
+	// Order is the order in which sections are written.
+	Order []modfmt.Section `toml:"order" yaml:"order"`
+
+	// MergeIndirect merges indirect dependencies into the same block as
+	// direct dependencies.
+	MergeIndirect bool `toml:"merge-indirect" yaml:"merge-indirect"`
+
+	// MergeLocal merges local replacements into the same block as module
+	// replacements.
+	MergeLocal bool `toml:"merge-local" yaml:"merge-local"`
+
+	// Collapse writes single-entry blocks as single-line directives.
+	Collapse bool `toml:"collapse" yaml:"collapse"`
+
+	// KeepGroups keeps blank-line separated groups of directives within
+	// blocks.
+	KeepGroups bool `toml:"keep-groups" yaml:"keep-groups"`
+
+	// Comments controls how comments are formatted.
+	Comments modfmt.CommentStyle `toml:"comments" yaml:"comments"`
+
+	// Dedupe merges duplicate `require`, `exclude`, and `tool` directives.
+	Dedupe bool `toml:"dedupe" yaml:"dedupe"`
+
+	// OnConflict controls how conflicting versions of the same required
+	// module are resolved when duplicates are merged.
+	OnConflict modfmt.ConflictPolicy `toml:"on-conflict" yaml:"on-conflict"`
+
+	// Canonical fixes non-canonical module versions, and reports invalid
+	// module paths.
+	Canonical bool `toml:"canonical" yaml:"canonical"`
+
+	// ResolveConflicts merges both sides of any git conflicts in `go.mod`
+	// files.
+	ResolveConflicts bool `toml:"resolve-conflicts" yaml:"resolve-conflicts"`
+
+	// Rules are the names of the rules checked by `modfmt lint`. Defaults to
+	// the built-in rules which report mistakes.
+	Rules []string `toml:"rules" yaml:"rules"`
+
+	// Enable are the names of additional rules checked by `modfmt lint`, such
+	// as the built-in policy rules which are not checked by default.
+	Enable []string `toml:"enable" yaml:"enable"`
+}
+
+// options returns the modfmt.Options equivalent of this config.
+func (c config) options() modfmt.Options {
+	return modfmt.Options{
+		Order:            c.Order,
+		MergeIndirect:    c.MergeIndirect,
+		MergeLocal:       c.MergeLocal,
+		Collapse:         c.Collapse,
+		KeepGroups:       c.KeepGroups,
+		Comments:         c.Comments,
+		Dedupe:           c.Dedupe,
+		Conflicts:        c.OnConflict,
+		Canonical:        c.Canonical,
+		ResolveConflicts: c.ResolveConflicts,
+	}
+}
+
+// rules returns the configured lint rules, along with any enabled rules.
+func (c config) rules() ([]lint.Rule, error) {
+	rules := lint.DefaultRules()
+
+	if len(c.Rules) > 0 {
+		rules = make([]lint.Rule, 0, len(c.Rules)+len(c.Enable))
+
+		for _, name := range c.Rules {
+			rule, ok := lint.Lookup(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown lint rule %q", name)
+			}
+
+			rules = append(rules, rule)
+		}
+	}
+
+	for _, name := range c.Enable {
+		rule, ok := lint.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+
+		if !slices.ContainsFunc(rules, func(r lint.Rule) bool { return r.Name() == name }) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// override updates this config with the value of any of the given flags that
+// were explicitly set.
+func (c *config) override(flags *pflag.FlagSet) error {
+	var err error
+
+	if flags.Changed("order") {
+		var order []string
+		if order, err = flags.GetStringSlice("order"); err != nil {
+			return err
+		}
+
+		c.Order = make([]modfmt.Section, 0, len(order))
+		for _, section := range order {
+			c.Order = append(c.Order, modfmt.Section(section))
+		}
+	}
+
+	if flags.Changed("merge-indirect") {
+		if c.MergeIndirect, err = flags.GetBool("merge-indirect"); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("merge-local") {
+		if c.MergeLocal, err = flags.GetBool("merge-local"); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("collapse") {
+		if c.Collapse, err = flags.GetBool("collapse"); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("keep-groups") {
+		if c.KeepGroups, err = flags.GetBool("keep-groups"); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("comments") {
+		var comments string
+		if comments, err = flags.GetString("comments"); err != nil {
+			return err
+		}
+
+		c.Comments = modfmt.CommentStyle(comments)
+	}
+
+	if flags.Changed("rules") {
+		if c.Rules, err = flags.GetStringSlice("rules"); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("enable") {
+		if c.Enable, err = flags.GetStringSlice("enable"); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("canonical") {
+		if c.Canonical, err = flags.GetBool("canonical"); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("resolve-conflicts") {
+		if c.ResolveConflicts, err = flags.GetBool("resolve-conflicts"); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("dedupe") {
+		if c.Dedupe, err = flags.GetBool("dedupe"); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("on-conflict") {
+		var onConflict string
+		if onConflict, err = flags.GetString("on-conflict"); err != nil {
+			return err
+		}
+
+		c.OnConflict = modfmt.ConflictPolicy(onConflict)
+	}
+
+	return nil
+}
+
+// readConfig reads and decodes the given configuration file. The file is
+// decoded as TOML if it has a `.toml` extension, and as YAML otherwise.
+// Unknown settings are treated as an error.
+func readConfig(filename string) (config, error) {
+	var cfg config
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return cfg, err
+	}
+
+	if filepath.Ext(filename) == ".toml" {
+		meta, err := toml.Decode(string(data), &cfg)
+		if err != nil {
+			return cfg, fmt.Errorf("%s: %w", filename, err)
+		}
+
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return cfg, fmt.Errorf("%s: unknown setting %q", filename, undecoded[0].String())
+		}
+
+		return cfg, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	// An empty file is a valid (empty) configuration.
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return cfg, nil
+}
+
+// findConfig searches the given directory, and each of its parent
+// directories, for a configuration file. Returns an empty string if no
+// configuration file was found.
+func findConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		for _, name := range configNames {
+			filename := filepath.Join(dir, name)
+
+			_, err := os.Stat(filename)
+			switch {
+			case err == nil:
+				// Found a configuration file!
+				return filename, nil
+
+			case !errors.Is(err, fs.ErrNotExist):
+				// There was an actual error.
+				return "", err
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Reached the filesystem root.
+			return "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// configResolver resolves the effective configuration for individual files.
+type configResolver struct {
+	// explicit is the name of a configuration file to use for every file,
+	// instead of searching for one.
+	explicit string
+
+	// flags are the command line flags which override configuration files.
+	flags *pflag.FlagSet
+
+	// cache holds previously read configuration files by name.
+	cache map[string]config
+
+	// mu guards cache, since files may be resolved concurrently.
+	mu sync.Mutex
+}
+
+// resolve returns the effective configuration for the given file, along with
+// the name of the configuration file it was read from (if any).
+func (r *configResolver) resolve(filename string) (config, string, error) {
+	source := r.explicit
+
+	if source == "" {
+		var err error
+		if source, err = findConfig(filepath.Dir(filename)); err != nil {
+			return config{}, "", err
+		}
+	}
+
+	var cfg config
+
+	if source != "" {
+		var err error
+		if cfg, err = r.read(source); err != nil {
+			return config{}, "", err
+		}
+	}
+
+	// Explicitly set flags take precedence over the configuration file.
+	if err := cfg.override(r.flags); err != nil {
+		return config{}, "", err
+	}
+
+	if cfg.Comments == "" {
+		cfg.Comments = modfmt.CommentsNormalize
+	}
+
+	if cfg.OnConflict == "" {
+		cfg.OnConflict = modfmt.ConflictHighest
+	}
+
+	return cfg, source, nil
+}
+
+// read returns the decoded contents of the given configuration file, reading
+// the file only once.
+func (r *configResolver) read(source string) (config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, ok := r.cache[source]; ok {
+		return cached, nil
+	}
+
+	cfg, err := readConfig(source)
+	if err != nil {
+		return config{}, err
+	}
+
+	r.cache[source] = cfg
+
+	return cfg, nil
+}
+
+// printConfigFor writes the given effective configuration for the given file
+// as YAML, along with the name of the configuration file it was read from.
+func printConfigFor(w io.Writer, filename, source string, cfg config) error {
+	if source == "" {
+		source = "defaults"
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "# %s (%s)\n%s", filename, source, data)
+
+	return nil
+}
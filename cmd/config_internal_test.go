@@ -0,0 +1,327 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+func TestConfigResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+
+		// files are the configuration files to create, relative to a
+		// temporary directory.
+		files map[string]string
+
+		// filename is the file to resolve the configuration for.
+		filename string
+
+		// args are command line flags, where $DIR is replaced with the
+		// temporary directory.
+		args []string
+
+		// source is the expected configuration file, or empty if none.
+		source string
+
+		expected config
+
+		// err is the expected error, where $DIR is replaced with the temporary
+		// directory.
+		err string
+	}{
+		{
+			name:     "defaults",
+			filename: "go.mod",
+			expected: config{Comments: modfmt.CommentsNormalize, OnConflict: modfmt.ConflictHighest},
+		},
+		{
+			name: "yaml",
+			files: map[string]string{
+				".modfmt.yaml": "collapse: true\norder: [module, go]\non-conflict: error\n",
+			},
+			filename: "go.mod",
+			source:   ".modfmt.yaml",
+			expected: config{
+				Order:      []modfmt.Section{modfmt.SectionModule, modfmt.SectionGo},
+				Collapse:   true,
+				Comments:   modfmt.CommentsNormalize,
+				OnConflict: modfmt.ConflictError,
+			},
+		},
+		{
+			name: "yaml before yml and toml",
+			files: map[string]string{
+				".modfmt.yaml": "collapse: true\n",
+				".modfmt.yml":  "merge-local: true\n",
+				".modfmt.toml": "keep-groups = true\n",
+			},
+			filename: "go.mod",
+			source:   ".modfmt.yaml",
+			expected: config{Collapse: true, Comments: modfmt.CommentsNormalize, OnConflict: modfmt.ConflictHighest},
+		},
+		{
+			name: "yml before toml",
+			files: map[string]string{
+				".modfmt.yml":  "merge-local: true\n",
+				".modfmt.toml": "keep-groups = true\n",
+			},
+			filename: "go.mod",
+			source:   ".modfmt.yml",
+			expected: config{MergeLocal: true, Comments: modfmt.CommentsNormalize, OnConflict: modfmt.ConflictHighest},
+		},
+		{
+			name: "toml",
+			files: map[string]string{
+				".modfmt.toml": "keep-groups = true\ncomments = \"preserve\"\nrules = [\"duplicate-require\"]\n",
+			},
+			filename: "go.mod",
+			source:   ".modfmt.toml",
+			expected: config{
+				KeepGroups: true,
+				Comments:   modfmt.CommentsPreserve,
+				OnConflict: modfmt.ConflictHighest,
+				Rules:      []string{"duplicate-require"},
+			},
+		},
+		{
+			name: "parent directory",
+			files: map[string]string{
+				".modfmt.yaml": "collapse: true\n",
+			},
+			filename: "a/b/go.mod",
+			source:   ".modfmt.yaml",
+			expected: config{Collapse: true, Comments: modfmt.CommentsNormalize, OnConflict: modfmt.ConflictHighest},
+		},
+		{
+			name: "nearest directory",
+			files: map[string]string{
+				".modfmt.yaml":   "collapse: true\n",
+				"a/.modfmt.toml": "merge-indirect = true\n",
+			},
+			filename: "a/b/go.mod",
+			source:   "a/.modfmt.toml",
+			expected: config{MergeIndirect: true, Comments: modfmt.CommentsNormalize, OnConflict: modfmt.ConflictHighest},
+		},
+		{
+			name: "flags override",
+			files: map[string]string{
+				".modfmt.yaml": "collapse: true\nmerge-local: true\norder: [module, go]\n",
+			},
+			filename: "go.mod",
+			args:     []string{"--collapse=false", "--order=go", "--comments=preserve"},
+			source:   ".modfmt.yaml",
+			expected: config{
+				Order:      []modfmt.Section{modfmt.SectionGo},
+				MergeLocal: true,
+				Comments:   modfmt.CommentsPreserve,
+				OnConflict: modfmt.ConflictHighest,
+			},
+		},
+		{
+			name: "explicit config",
+			files: map[string]string{
+				".modfmt.yaml":      "collapse: true\n",
+				"other/config.toml": "dedupe = true\n",
+			},
+			filename: "go.mod",
+			args:     []string{"--config=$DIR/other/config.toml"},
+			source:   "other/config.toml",
+			expected: config{Dedupe: true, Comments: modfmt.CommentsNormalize, OnConflict: modfmt.ConflictHighest},
+		},
+		{
+			name: "empty yaml",
+			files: map[string]string{
+				".modfmt.yaml": "",
+			},
+			filename: "go.mod",
+			source:   ".modfmt.yaml",
+			expected: config{Comments: modfmt.CommentsNormalize, OnConflict: modfmt.ConflictHighest},
+		},
+		{
+			name: "empty toml",
+			files: map[string]string{
+				".modfmt.toml": "",
+			},
+			filename: "go.mod",
+			source:   ".modfmt.toml",
+			expected: config{Comments: modfmt.CommentsNormalize, OnConflict: modfmt.ConflictHighest},
+		},
+		{
+			name: "unknown yaml setting",
+			files: map[string]string{
+				".modfmt.yaml": "bogus: true\n",
+			},
+			filename: "go.mod",
+			err:      "$DIR/.modfmt.yaml: yaml: unmarshal errors:\n  line 1: field bogus not found in type cmd.config",
+		},
+		{
+			name: "unknown toml setting",
+			files: map[string]string{
+				".modfmt.toml": "bogus = true\n",
+			},
+			filename: "go.mod",
+			err:      `$DIR/.modfmt.toml: unknown setting "bogus"`,
+		},
+		{
+			name:     "missing explicit config",
+			filename: "go.mod",
+			args:     []string{"--config=$DIR/missing.yaml"},
+			err:      "open $DIR/missing.yaml: no such file or directory",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			for name, content := range test.files {
+				filename := filepath.Join(dir, filepath.FromSlash(name))
+
+				if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd := Command()
+
+			args := make([]string, 0, len(test.args))
+			for _, arg := range test.args {
+				args = append(args, strings.ReplaceAll(arg, "$DIR", dir))
+			}
+
+			if err := cmd.ParseFlags(args); err != nil {
+				t.Fatal(err)
+			}
+
+			explicit, err := cmd.Flags().GetString("config")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resolver := &configResolver{
+				explicit: explicit,
+				flags:    cmd.Flags(),
+				cache:    make(map[string]config),
+			}
+
+			cfg, source, err := resolver.resolve(filepath.Join(dir, filepath.FromSlash(test.filename)))
+
+			expectedErr := strings.ReplaceAll(test.err, "$DIR", dir)
+
+			switch {
+			case test.err != "" && err == nil:
+				t.Fatal("expected an error")
+			case test.err != "" && err.Error() != expectedErr:
+				t.Fatalf("expected error %q, actual %q", expectedErr, err.Error())
+			case test.err == "" && err != nil:
+				t.Fatal(err)
+			case test.err != "":
+				return
+			}
+
+			var expectedSource string
+			if test.source != "" {
+				expectedSource = filepath.Join(dir, filepath.FromSlash(test.source))
+			}
+
+			if source != expectedSource {
+				t.Fatalf("expected source %q, actual %q", expectedSource, source)
+			}
+
+			if !reflect.DeepEqual(cfg, test.expected) {
+				t.Fatalf("expected:\n%+v\nactual:\n%+v", test.expected, cfg)
+			}
+		})
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		".modfmt.yaml":   "collapse: true\nrules: [duplicate-require]\n",
+		"a/go.mod":       "module example.com/a\n",
+		"b/go.mod":       "module example.com/b\n",
+		"b/.modfmt.toml": "dedupe = true\n",
+	}
+
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := filepath.Join(dir, "a", "go.mod")
+	b := filepath.Join(dir, "b", "go.mod")
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := Command()
+	cmd.SetArgs([]string{"--print-config", "--merge-indirect", a, b})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# " + a + " (" + filepath.Join(dir, ".modfmt.yaml") + `)
+order: []
+merge-indirect: true
+merge-local: false
+collapse: true
+keep-groups: false
+comments: normalize
+dedupe: false
+on-conflict: highest
+canonical: false
+resolve-conflicts: false
+rules:
+    - duplicate-require
+enable: []
+# ` + b + " (" + filepath.Join(dir, "b", ".modfmt.toml") + `)
+order: []
+merge-indirect: true
+merge-local: false
+collapse: false
+keep-groups: false
+comments: normalize
+dedupe: true
+on-conflict: highest
+canonical: false
+resolve-conflicts: false
+rules: []
+enable: []
+`
+
+	if stdout.String() != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, stdout.String())
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// operation is a single step in an edit script that transforms one list of
+// lines into another.
+type operation struct {
+	// kind is one of ' ' (unchanged), '-' (deleted), or '+' (inserted).
+	kind byte
+
+	// line is the content of the line, including any trailing newline.
+	line string
+}
+
+// hunk represents a contiguous region of changed lines, surrounded by
+// unchanged context lines.
+type hunk struct {
+	// oldStart and oldLines are the 1-indexed starting line and line count
+	// of this hunk in the original file.
+	oldStart, oldLines int
+
+	// newStart and newLines are the 1-indexed starting line and line count
+	// of this hunk in the formatted file.
+	newStart, newLines int
+
+	// operations are the context, deleted, and inserted lines in this hunk.
+	operations []operation
+}
+
+// unifiedDiff returns a unified diff between the original and formatted
+// contents of the given file. Returns an empty string if the contents are
+// identical.
+func unifiedDiff(filename string, original, formatted []byte) string {
+	hunks := diffHunks(original, formatted)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+
+	// Emit headers in the same style as `gofmt -d`.
+	fmt.Fprintf(&result, "diff %s.orig %s\n", filename, filename)
+	fmt.Fprintf(&result, "--- %s.orig\n", filename)
+	fmt.Fprintf(&result, "+++ %s\n", filename)
+
+	for _, h := range hunks {
+		fmt.Fprintf(&result, "@@ -%s +%s @@\n", hunkRange(h.oldStart, h.oldLines), hunkRange(h.newStart, h.newLines))
+
+		for _, op := range h.operations {
+			result.WriteByte(op.kind)
+			result.WriteString(op.line)
+
+			if !strings.HasSuffix(op.line, "\n") {
+				result.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return result.String()
+}
+
+// hunkRange formats a hunk range as used in a unified diff hunk header.
+func hunkRange(start, lines int) string {
+	switch lines {
+	case 0:
+		// An empty range refers to the line before the change.
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	default:
+		return fmt.Sprintf("%d,%d", start, lines)
+	}
+}
+
+// diffHunks computes the list of hunks needed to transform the original
+// contents into the formatted contents.
+func diffHunks(original, formatted []byte) []hunk {
+	operations := editScript(splitLines(string(original)), splitLines(string(formatted)))
+
+	var (
+		hunks   []hunk
+		current *hunk
+		oldLine = 1
+		newLine = 1
+	)
+
+	for index, op := range operations {
+		if op.kind == ' ' {
+			// Unchanged lines are only included when they are within range
+			// of a change.
+			if current != nil && withinContext(operations, index) {
+				current.operations = append(current.operations, op)
+				current.oldLines++
+				current.newLines++
+			}
+
+			if current != nil && !withinContext(operations, index) {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+
+			oldLine++
+			newLine++
+
+			continue
+		}
+
+		if current == nil {
+			// Start a new hunk, including any preceding context lines.
+			start := max(0, index-contextLines)
+			for start < index && operations[start].kind != ' ' {
+				start++
+			}
+
+			current = &hunk{
+				oldStart:   oldLine - (index - start),
+				oldLines:   index - start,
+				newStart:   newLine - (index - start),
+				newLines:   index - start,
+				operations: append([]operation(nil), operations[start:index]...),
+			}
+		}
+
+		current.operations = append(current.operations, op)
+
+		switch op.kind {
+		case '-':
+			current.oldLines++
+			oldLine++
+		case '+':
+			current.newLines++
+			newLine++
+		}
+	}
+
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+
+	return hunks
+}
+
+// withinContext reports if the unchanged operation at the given index is
+// within contextLines of a preceding or following change.
+func withinContext(operations []operation, index int) bool {
+	for i := index - 1; i >= 0 && i >= index-contextLines; i-- {
+		if operations[i].kind != ' ' {
+			return true
+		}
+	}
+
+	for i := index + 1; i < len(operations) && i <= index+contextLines; i++ {
+		if operations[i].kind != ' ' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// editScript computes a minimal list of operations that transforms lines a
+// into lines b, using the longest common subsequence between them.
+func editScript(a, b []string) []operation {
+	// lcs[i][j] holds the length of the longest common subsequence between
+	// a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	operations := make([]operation, 0, max(len(a), len(b)))
+
+	var i, j int
+
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			operations = append(operations, operation{kind: ' ', line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			operations = append(operations, operation{kind: '-', line: a[i]})
+			i++
+		default:
+			operations = append(operations, operation{kind: '+', line: b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		operations = append(operations, operation{kind: '-', line: a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		operations = append(operations, operation{kind: '+', line: b[j]})
+	}
+
+	return operations
+}
+
+// splitLines splits the given text into lines, where each line retains its
+// trailing newline. The final line will lack a newline if the text did not
+// end with one.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+
+	// Drop the empty element that follows a trailing newline.
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
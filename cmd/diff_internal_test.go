@@ -0,0 +1,274 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEditScript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "identical",
+			a:        "a b c",
+			b:        "a b c",
+			expected: " a  b  c",
+		},
+		{
+			name:     "insert all",
+			b:        "a b",
+			expected: "+a +b",
+		},
+		{
+			name:     "delete all",
+			a:        "a b",
+			expected: "-a -b",
+		},
+		{
+			name:     "insert start",
+			a:        "b c",
+			b:        "a b c",
+			expected: "+a  b  c",
+		},
+		{
+			name:     "insert end",
+			a:        "a b",
+			b:        "a b c",
+			expected: " a  b +c",
+		},
+		{
+			name:     "replace middle",
+			a:        "a b c",
+			b:        "a x c",
+			expected: " a -b +x  c",
+		},
+		{
+			name:     "move",
+			a:        "a b c",
+			b:        "b c a",
+			expected: "-a  b  c +a",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			operations := editScript(strings.Fields(test.a), strings.Fields(test.b))
+
+			actual := make([]string, 0, len(operations))
+			for _, op := range operations {
+				actual = append(actual, string(op.kind)+op.line)
+			}
+
+			if strings.Join(actual, " ") != test.expected {
+				t.Fatalf("expected %q, actual %q", test.expected, strings.Join(actual, " "))
+			}
+		})
+	}
+}
+
+func TestDiffHunks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		original  string
+		formatted string
+		expected  string
+	}{
+		{
+			name:      "identical",
+			original:  "1\n2\n3\n",
+			formatted: "1\n2\n3\n",
+		},
+		{
+			name:      "insert start",
+			original:  "1\n2\n3\n4\n5\n",
+			formatted: "0\n1\n2\n3\n4\n5\n",
+			expected: `-1,3 +1,4
++0
+ 1
+ 2
+ 3
+`,
+		},
+		{
+			name:      "delete start",
+			original:  "0\n1\n2\n3\n4\n5\n",
+			formatted: "1\n2\n3\n4\n5\n",
+			expected: `-1,4 +1,3
+-0
+ 1
+ 2
+ 3
+`,
+		},
+		{
+			name:      "insert end",
+			original:  "1\n2\n3\n4\n5\n",
+			formatted: "1\n2\n3\n4\n5\n6\n",
+			expected: `-3,3 +3,4
+ 3
+ 4
+ 5
++6
+`,
+		},
+		{
+			name:      "delete end",
+			original:  "1\n2\n3\n4\n5\n6\n",
+			formatted: "1\n2\n3\n4\n5\n",
+			expected: `-3,4 +3,3
+ 3
+ 4
+ 5
+-6
+`,
+		},
+		{
+			name:      "newline at end",
+			original:  "1\n2",
+			formatted: "1\n2\n",
+			expected: `-1,2 +1,2
+ 1
+-2
++2
+`,
+		},
+		{
+			name:      "nearby changes",
+			original:  "1\n2\n3\n4\n5\n6\n7\n",
+			formatted: "x\n2\n3\n4\n5\n6\ny\n",
+			expected: `-1,7 +1,7
+-1
++x
+ 2
+ 3
+ 4
+ 5
+ 6
+-7
++y
+`,
+		},
+		{
+			name:      "distant changes",
+			original:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			formatted: "x\n2\n3\n4\n5\n6\n7\n8\ny\n",
+			expected: `-1,4 +1,4
+-1
++x
+ 2
+ 3
+ 4
+-6,4 +6,4
+ 6
+ 7
+ 8
+-9
++y
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual strings.Builder
+
+			for _, h := range diffHunks([]byte(test.original), []byte(test.formatted)) {
+				fmt.Fprintf(&actual, "-%d,%d +%d,%d\n", h.oldStart, h.oldLines, h.newStart, h.newLines)
+
+				for _, op := range h.operations {
+					actual.WriteByte(op.kind)
+					actual.WriteString(strings.TrimSuffix(op.line, "\n") + "\n")
+				}
+			}
+
+			if actual.String() != test.expected {
+				t.Fatalf("expected:\n%s\nactual:\n%s", test.expected, actual.String())
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		original  string
+		formatted string
+		expected  string
+	}{
+		{
+			name:      "identical",
+			original:  "module example.com/foo/bar\n",
+			formatted: "module example.com/foo/bar\n",
+		},
+		{
+			name:      "insert start",
+			original:  "go 1.23.0\n",
+			formatted: "module example.com/foo/bar\n\ngo 1.23.0\n",
+			expected: `diff go.mod.orig go.mod
+--- go.mod.orig
++++ go.mod
+@@ -1 +1,3 @@
++module example.com/foo/bar
++
+ go 1.23.0
+`,
+		},
+		{
+			name:      "empty original",
+			formatted: "module example.com/foo/bar\n",
+			expected: `diff go.mod.orig go.mod
+--- go.mod.orig
++++ go.mod
+@@ -0,0 +1 @@
++module example.com/foo/bar
+`,
+		},
+		{
+			name:      "newline at end",
+			original:  "module example.com/foo/bar",
+			formatted: "module example.com/foo/bar\n",
+			expected: `diff go.mod.orig go.mod
+--- go.mod.orig
++++ go.mod
+@@ -1 +1 @@
+-module example.com/foo/bar
+\ No newline at end of file
++module example.com/foo/bar
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := unifiedDiff("go.mod", []byte(test.original), []byte(test.formatted))
+			if actual != test.expected {
+				t.Fatalf("expected:\n%s\nactual:\n%s", test.expected, actual)
+			}
+		})
+	}
+}
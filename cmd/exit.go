@@ -0,0 +1,97 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// ExitUnformatted is the exit code used when check mode was requested and
+	// any files were unformatted.
+	ExitUnformatted = 1
+
+	// ExitErrors is the exit code used when any files could not be read,
+	// formatted, or written.
+	ExitErrors = 2
+
+	// ExitConflicts is the exit code used by `modfmt merge-driver` when a
+	// merge left conflicts that must be resolved by hand.
+	ExitConflicts = 1
+)
+
+// ExitError is an error which should cause modfmt to exit with a specific
+// exit code.
+type ExitError struct {
+	// Code is the exit code to use.
+	Code int
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code to use for the given error returned by
+// Command. Any error which does not carry its own exit code, such as an invalid
+// flag or configuration file, is treated as ExitErrors.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return ExitErrors
+}
+
+// unjoin splits an error created by errors.Join into its individual errors.
+// Returns nil if the error is nil.
+func unjoin(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint
+		return joined.Unwrap()
+	}
+
+	return []error{err}
+}
+
+// summarize returns an error describing how many of the given total files
+// could not be processed (e.g. "formatted"), how many were skipped, and if any
+// paths could not be searched.
+func summarize(action string, total, failed, skipped int, discoverErr error) error {
+	var parts []string
+
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d files could not be %s", failed, total, action))
+	}
+
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d files were skipped", skipped))
+	}
+
+	if discoverErr != nil {
+		parts = append(parts, fmt.Sprintf("%d paths could not be searched", len(unjoin(discoverErr))))
+	}
+
+	return errors.New(strings.Join(parts, ", "))
+}
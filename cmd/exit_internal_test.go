@@ -0,0 +1,139 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"formatted/go.mod":   "module example.com/foo/bar\n",
+		"unformatted/go.mod": "module example.com/foo/bar\ngo 1.23.0\n",
+		"invalid/go.mod":     "module example.com/foo/bar\nbogus\n",
+		"config.yaml":        "bogus: true\n",
+	}
+
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := func(name string) string {
+		return filepath.Join(dir, filepath.FromSlash(name))
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{
+			name: "formatted",
+			args: []string{"-l", path("formatted/go.mod")},
+		},
+		{
+			name:     "unformatted",
+			args:     []string{"-c", path("unformatted/go.mod")},
+			expected: ExitUnformatted,
+		},
+		{
+			name:     "invalid file",
+			args:     []string{"-l", path("invalid/go.mod")},
+			expected: ExitErrors,
+		},
+		{
+			name:     "missing file",
+			args:     []string{"-l", path("missing/go.mod")},
+			expected: ExitErrors,
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"--bogus", path("formatted/go.mod")},
+			expected: ExitErrors,
+		},
+		{
+			name:     "unknown format",
+			args:     []string{"--format=xml", path("formatted/go.mod")},
+			expected: ExitErrors,
+		},
+		{
+			name:     "no jobs",
+			args:     []string{"--jobs=0", path("formatted/go.mod")},
+			expected: ExitErrors,
+		},
+		{
+			name:     "unknown section",
+			args:     []string{"--order=bogus", path("formatted/go.mod")},
+			expected: ExitErrors,
+		},
+		{
+			name:     "invalid config",
+			args:     []string{"--config", path("config.yaml"), "--print-config", path("formatted/go.mod")},
+			expected: ExitErrors,
+		},
+		{
+			name:     "write standard input",
+			args:     []string{"-w", "-"},
+			expected: ExitErrors,
+		},
+		{
+			name:     "unknown revision",
+			args:     []string{"--changed-since=refs/heads/does-not-exist", path("formatted/go.mod")},
+			expected: ExitErrors,
+		},
+		{
+			name:     "lint unformatted",
+			args:     []string{"lint", path("unformatted/go.mod")},
+			expected: ExitUnformatted,
+		},
+		{
+			name:     "lint unknown rule",
+			args:     []string{"lint", "--rules=bogus", path("formatted/go.mod")},
+			expected: ExitErrors,
+		},
+		{
+			name:     "diff missing argument",
+			args:     []string{"diff", path("formatted/go.mod")},
+			expected: ExitErrors,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+
+			cmd := Command()
+			cmd.SetArgs(test.args)
+			cmd.SetIn(strings.NewReader(""))
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+
+			err := cmd.Execute()
+
+			if actual := ExitCode(err); actual != test.expected {
+				t.Fatalf("expected exit code %d, actual %d (%v)", test.expected, actual, err)
+			}
+		})
+	}
+}
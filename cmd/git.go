@@ -0,0 +1,228 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// indexMu serializes updates to the git index, since git refuses to update
+// the index while another update holds its lock.
+var indexMu sync.Mutex
+
+// git runs the local git binary with the given arguments, in the current
+// working directory, and returns its standard output. The given input, if
+// any, is used as standard input. Only the first line of any error message is
+// kept, since git may follow it with a lengthy usage message.
+func git(input []byte, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// changedFiles returns the set of `go.mod` and `go.work` files within the
+// current repository which differ from the given revision. If staged is true,
+// then the index is compared instead of the working tree, and the revision
+// defaults to `HEAD`. Otherwise, untracked files are also included. Deleted
+// files are never included. File names are absolute.
+func changedFiles(revision string, staged bool) (map[string]bool, error) {
+	if !staged && revision == "" {
+		return nil, errors.New("either a revision or the index must be compared")
+	}
+
+	// Find the repository root, relative to the current working directory, as
+	// git lists files relative to the root.
+	cdup, err := git(nil, "rev-parse", "--show-cdup")
+	if err != nil {
+		return nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(cwd, filepath.FromSlash(strings.TrimSpace(string(cdup))))
+
+	args := []string{"diff", "--name-only", "--diff-filter=d", "-z"}
+	if staged {
+		args = append(args, "--cached")
+	}
+
+	if revision != "" {
+		args = append(args, revision)
+	}
+
+	output, err := git(nil, append(args, "--")...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !staged {
+		// Files which have never been added are not known to `git diff`.
+		untracked, err := git(nil, "ls-files", "--others", "--exclude-standard", "--full-name", "-z", "--", ":/")
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, untracked...)
+	}
+
+	results := make(map[string]bool)
+
+	for name := range strings.SplitSeq(string(output), "\x00") {
+		switch filepath.Base(name) {
+		case "go.mod", "go.work":
+			results[filepath.Join(root, filepath.FromSlash(name))] = true
+		}
+	}
+
+	return results, nil
+}
+
+// indexEntry describes a single file staged in the git index.
+type indexEntry struct {
+	// mode is the file mode, such as `100644`.
+	mode string
+
+	// object is the name of the blob holding the staged contents.
+	object string
+
+	// path is the name of the file, relative to the repository root.
+	path string
+}
+
+// stagedEntry returns the index entry for the given file, which is relative to
+// the current working directory.
+func stagedEntry(filename string) (indexEntry, error) {
+	output, err := git(nil, "ls-files", "--stage", "--full-name", "-z", "--", filename)
+	if err != nil {
+		return indexEntry{}, err
+	}
+
+	// Each entry has the form `<mode> <object> <stage>\t<path>`.
+	line, _, _ := strings.Cut(string(output), "\x00")
+
+	info, path, ok := strings.Cut(line, "\t")
+	fields := strings.Fields(info)
+
+	switch {
+	case !ok || len(fields) != 3:
+		return indexEntry{}, fmt.Errorf("%s: not staged in the git index", filename)
+	case fields[2] != "0":
+		return indexEntry{}, fmt.Errorf("%s: has unresolved merge conflicts", filename)
+	}
+
+	return indexEntry{mode: fields[0], object: fields[1], path: path}, nil
+}
+
+// formatIndexFile formats the contents of the given file which are staged in
+// the git index, rather than the contents of the working tree. If write is
+// true, then the formatted contents are written back to the index, and to the
+// working tree.
+func formatIndexFile(filename string, resolver *configResolver, write bool, backup string) result {
+	res := result{filename: filename}
+
+	entry, err := stagedEntry(filename)
+	if err != nil {
+		res.err = err
+
+		return res
+	}
+
+	// Read the staged file.
+	if res.original, res.err = git(nil, "cat-file", "blob", entry.object); res.err != nil {
+		return res
+	}
+
+	// Resolve the configuration for the file.
+	cfg, _, err := resolver.resolve(filename)
+	if err != nil {
+		res.err = fmt.Errorf("%s: %w", filename, err)
+
+		return res
+	}
+
+	// Format the file.
+	if res.formatted, res.err = modfmt.FormatWithOptions(filename, res.original, cfg.options()); res.err != nil {
+		return res
+	}
+
+	if cfg.Canonical {
+		if res.diagnostics, res.err = checkFile(filename, res.original, res.formatted, cfg); res.err != nil {
+			return res
+		}
+	}
+
+	if write && res.unformatted() {
+		res.err = writeIndexFile(filename, entry, res.original, res.formatted, cfg.options(), backup)
+	}
+
+	return res
+}
+
+// writeIndexFile writes the given formatted contents to the git index, and
+// then updates the working tree. If the working tree file matches the
+// original staged contents then it is replaced outright. Otherwise the file
+// has unstaged changes, which are kept by formatting the working tree file
+// separately.
+func writeIndexFile(filename string, entry indexEntry, original, formatted []byte, opts modfmt.Options, backup string) error { //nolint:lll
+	output, err := git(formatted, "hash-object", "-w", "--stdin", "--path", filename)
+	if err != nil {
+		return err
+	}
+
+	object := strings.TrimSpace(string(output))
+
+	indexMu.Lock()
+	_, err = git(nil, "update-index", "--cacheinfo", entry.mode+","+object+","+entry.path)
+	indexMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(current, original) {
+		// Unstaged changes may leave the working tree file unparsable, in
+		// which case it is left as-is.
+		if formatted, err = modfmt.FormatWithOptions(filename, current, opts); err != nil {
+			return nil //nolint:nilerr
+		}
+	}
+
+	if bytes.Equal(current, formatted) {
+		return nil
+	}
+
+	return writeFile(filename, formatted, current, backup)
+}
@@ -0,0 +1,335 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// indexOriginal is an unformatted go.mod file staged in the git index.
+	indexOriginal = "module example.com/foo/bar\nrequire example.com/a/a v1.0.0\n"
+
+	// indexFormatted is indexOriginal after formatting.
+	indexFormatted = "module example.com/foo/bar\n\nrequire (\n\texample.com/a/a v1.0.0\n)\n"
+
+	// indexChanged is an unformatted change to indexOriginal.
+	indexChanged = indexOriginal + "require example.com/b/b v1.0.0\n"
+)
+
+func TestFormatIndexFile(t *testing.T) { //nolint:paralleltest
+	tests := []struct {
+		name string
+
+		// staged is false if the file is never added to the index.
+		staged bool
+
+		// worktree is the contents of the working tree file after staging.
+		// Defaults to the staged contents.
+		worktree string
+
+		write            bool
+		expectedIndex    string
+		expectedWorktree string
+		err              string
+	}{
+		{
+			name:             "check",
+			staged:           true,
+			expectedIndex:    indexOriginal,
+			expectedWorktree: indexOriginal,
+		},
+		{
+			name:             "write",
+			staged:           true,
+			write:            true,
+			expectedIndex:    indexFormatted,
+			expectedWorktree: indexFormatted,
+		},
+		{
+			name:             "write unstaged changes",
+			staged:           true,
+			worktree:         indexChanged,
+			write:            true,
+			expectedIndex:    indexFormatted,
+			expectedWorktree: "module example.com/foo/bar\n\nrequire (\n\texample.com/a/a v1.0.0\n\texample.com/b/b v1.0.0\n)\n", //nolint:lll
+		},
+		{
+			name:             "write unparsable changes",
+			staged:           true,
+			worktree:         indexOriginal + "bogus\n",
+			write:            true,
+			expectedIndex:    indexFormatted,
+			expectedWorktree: indexOriginal + "bogus\n",
+		},
+		{
+			name:             "not staged",
+			write:            true,
+			expectedWorktree: indexOriginal,
+			err:              "go.mod: not staged in the git index",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+
+			if _, err := git(nil, "init", "--quiet"); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile("go.mod", []byte(indexOriginal), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if test.staged {
+				if _, err := git(nil, "add", "go.mod"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if test.worktree != "" {
+				if err := os.WriteFile("go.mod", []byte(test.worktree), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			resolver := &configResolver{
+				flags: pflag.NewFlagSet("test", pflag.ContinueOnError),
+				cache: make(map[string]config),
+			}
+
+			res := formatIndexFile("go.mod", resolver, test.write, "")
+
+			switch {
+			case test.err != "" && res.err == nil:
+				t.Fatal("expected an error")
+			case test.err != "" && res.err.Error() != test.err:
+				t.Fatalf("expected error %q, actual %q", test.err, res.err.Error())
+			case test.err == "" && res.err != nil:
+				t.Fatal(res.err)
+			}
+
+			if test.err == "" {
+				if string(res.original) != indexOriginal {
+					t.Fatalf("expected original:\n%s\nactual:\n%s", indexOriginal, res.original)
+				}
+
+				if string(res.formatted) != indexFormatted {
+					t.Fatalf("expected formatted:\n%s\nactual:\n%s", indexFormatted, res.formatted)
+				}
+
+				index, err := git(nil, "show", ":go.mod")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(index) != test.expectedIndex {
+					t.Fatalf("expected index:\n%s\nactual:\n%s", test.expectedIndex, index)
+				}
+			}
+
+			worktree, err := os.ReadFile("go.mod")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(worktree) != test.expectedWorktree {
+				t.Fatalf("expected working tree:\n%s\nactual:\n%s", test.expectedWorktree, worktree)
+			}
+		})
+	}
+}
+
+func TestChangedFiles(t *testing.T) { //nolint:paralleltest
+	tests := []struct {
+		name     string
+		revision string
+		staged   bool
+		expected []string
+		err      string
+	}{
+		{
+			name:     "revision",
+			revision: "HEAD",
+			expected: []string{"a/go.mod", "b/go.mod", "b/go.work", "c/go.mod"},
+		},
+		{
+			name:     "staged",
+			staged:   true,
+			expected: []string{"b/go.mod"},
+		},
+		{
+			name:     "staged revision",
+			revision: "HEAD",
+			staged:   true,
+			expected: []string{"b/go.mod"},
+		},
+		{
+			name: "nothing",
+			err:  "either a revision or the index must be compared",
+		},
+		{
+			name:     "unknown revision",
+			revision: "refs/heads/does-not-exist",
+			err:      "git diff: fatal: bad revision 'refs/heads/does-not-exist'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := changedRepository(t)
+
+			// Files outside the current working directory are still found.
+			t.Chdir(filepath.Join(root, "a"))
+
+			changed, err := changedFiles(test.revision, test.staged)
+
+			switch {
+			case test.err != "" && err == nil:
+				t.Fatal("expected an error")
+			case test.err != "" && err.Error() != test.err:
+				t.Fatalf("expected error %q, actual %q", test.err, err.Error())
+			case test.err == "" && err != nil:
+				t.Fatal(err)
+			}
+
+			var actual []string
+
+			for name := range changed {
+				rel, err := filepath.Rel(root, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				actual = append(actual, filepath.ToSlash(rel))
+			}
+
+			slices.Sort(actual)
+
+			if strings.Join(actual, " ") != strings.Join(test.expected, " ") {
+				t.Fatalf("expected %q, actual %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestChangedFilesNotRepository(t *testing.T) { //nolint:paralleltest
+	t.Chdir(t.TempDir())
+
+	expected := "git rev-parse: fatal: not a git repository (or any of the parent directories): .git"
+
+	if _, err := changedFiles("HEAD", false); err == nil {
+		t.Fatal("expected an error")
+	} else if err.Error() != expected {
+		t.Fatalf("expected error %q, actual %q", expected, err.Error())
+	}
+}
+
+func TestCommandChanged(t *testing.T) { //nolint:paralleltest
+	root := changedRepository(t)
+
+	t.Chdir(filepath.Join(root, "a"))
+
+	changed, unchanged := filepath.Join(root, "b", "go.mod"), filepath.Join(root, "d", "go.mod")
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "current directory",
+			args:     []string{"--changed-since=HEAD", "-l"},
+			expected: "go.mod\n",
+		},
+		{
+			name:     "parent directory",
+			args:     []string{"--changed-since=HEAD", "-l", "../b/go.mod", "../d/go.mod"},
+			expected: "../b/go.mod\n",
+		},
+		{
+			name:     "absolute",
+			args:     []string{"--changed-since=HEAD", "-l", changed, unchanged},
+			expected: changed + "\n",
+		},
+		{
+			name:     "staged",
+			args:     []string{"--staged", "-l", "../..."},
+			expected: "../b/go.mod\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			cmd := Command()
+			cmd.SetArgs(test.args)
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+
+			if err := cmd.Execute(); err != nil && ExitCode(err) != ExitUnformatted {
+				t.Fatal(err)
+			}
+
+			if stdout.String() != filepath.FromSlash(test.expected) {
+				t.Fatalf("expected:\n%s\nactual:\n%s", test.expected, stdout.String())
+			}
+		})
+	}
+}
+
+// changedRepository creates a git repository with a commit, and then changes
+// some of the committed files. Returns the root of the repository.
+func changedRepository(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	write := func(content string, files ...string) {
+		t.Helper()
+
+		for _, name := range files {
+			filename := filepath.Join(root, filepath.FromSlash(name))
+
+			if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	run := func(args ...string) {
+		t.Helper()
+
+		if _, err := git(nil, append([]string{"-C", root}, args...)...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "--quiet")
+	write(indexOriginal, "a/go.mod", "b/go.mod", "d/go.mod")
+	run("add", ".")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--message=initial")
+
+	// Change committed files, stage one of them, and add untracked files.
+	write(indexChanged, "a/go.mod", "b/go.mod")
+	run("add", "b/go.mod")
+	write(indexChanged, "b/go.work", "c/go.mod")
+
+	return root
+}
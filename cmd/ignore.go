@@ -0,0 +1,309 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ignorePattern is a single pattern using the same syntax as `.gitignore`
+// files.
+//
+// See https://git-scm.com/docs/gitignore#_pattern_format
+type ignorePattern struct {
+	// base is the absolute directory that the pattern is relative to.
+	base string
+
+	// segments are the slash separated parts of the pattern.
+	segments []string
+
+	// anchored is true if the pattern only matches relative to base, rather
+	// than at any depth beneath it.
+	anchored bool
+
+	// dirOnly is true if the pattern only matches directories.
+	dirOnly bool
+
+	// negate is true if the pattern re-includes a previously ignored path.
+	negate bool
+}
+
+// parseIgnorePattern parses a single line from a `.gitignore` file, relative
+// to the given absolute directory. Returns false if the line is blank or a
+// comment.
+func parseIgnorePattern(base, line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, "\r")
+
+	// Trailing spaces are ignored unless escaped.
+	if !strings.HasSuffix(line, `\ `) {
+		line = strings.TrimRight(line, " ")
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	pattern := ignorePattern{base: base}
+
+	if rest, ok := strings.CutPrefix(line, "!"); ok {
+		pattern.negate = true
+		line = rest
+	}
+
+	// A leading backslash escapes a literal `#` or `!`.
+	if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+		line = line[1:]
+	}
+
+	if rest, ok := strings.CutSuffix(line, "/"); ok {
+		pattern.dirOnly = true
+		line = rest
+	}
+
+	// A pattern containing a slash anywhere but the end is relative to base.
+	if strings.Contains(line, "/") {
+		pattern.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	pattern.segments = strings.Split(line, "/")
+
+	return pattern, true
+}
+
+// match reports if the given absolute path is matched by this pattern.
+func (p ignorePattern) match(name string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+
+	rel, err := filepath.Rel(p.base, name)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		// The path is not beneath the base directory.
+		return false
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	if !p.anchored {
+		// An unanchored pattern is a single segment, and matches the name of
+		// the path at any depth.
+		ok, _ := path.Match(p.segments[0], parts[len(parts)-1])
+
+		return ok
+	}
+
+	return matchSegments(p.segments, parts)
+}
+
+// matchSegments reports if the given pattern segments match the given path
+// segments, where a `**` segment matches zero or more path segments. A
+// trailing `**` segment matches one or more path segments, so that `foo/**`
+// matches everything inside of `foo`, but not `foo` itself.
+func matchSegments(patterns, parts []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" && len(patterns) == 1 {
+			return len(parts) > 0
+		}
+
+		if patterns[0] == "**" {
+			for skip := 0; skip <= len(parts); skip++ {
+				if matchSegments(patterns[1:], parts[skip:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(parts) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(patterns[0], parts[0]); !ok {
+			return false
+		}
+
+		patterns, parts = patterns[1:], parts[1:]
+	}
+
+	return len(parts) == 0
+}
+
+// ignoreRules is an ordered list of ignore patterns, where later patterns
+// take precedence over earlier ones.
+type ignoreRules []ignorePattern
+
+// ignored reports if the given absolute path is ignored by these rules.
+func (r ignoreRules) ignored(name string, isDir bool) bool {
+	ignored := false
+
+	for _, pattern := range r {
+		if pattern.match(name, isDir) {
+			ignored = !pattern.negate
+		}
+	}
+
+	return ignored
+}
+
+// matched reports if the given absolute path is matched by any of these rules.
+func (r ignoreRules) matched(name string, isDir bool) bool {
+	for _, pattern := range r {
+		if pattern.match(name, isDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// load appends the patterns from the given ignore file, relative to the given
+// absolute directory. A missing file is not an error.
+func (r ignoreRules) load(base, filename string) (ignoreRules, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	} else if err != nil {
+		return r, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if pattern, ok := parseIgnorePattern(base, scanner.Text()); ok {
+			r = append(r, pattern)
+		}
+	}
+
+	return r, scanner.Err()
+}
+
+// pathFilter decides which paths are skipped while walking directories.
+type pathFilter struct {
+	// noIgnore disables `.gitignore` and `.git/info/exclude` rules.
+	noIgnore bool
+
+	// exclude are patterns for paths which are always skipped.
+	exclude ignoreRules
+
+	// include are patterns for files which are found. If empty, then all
+	// files are found.
+	include ignoreRules
+}
+
+// newPathFilter returns a filter using the given exclude and include
+// patterns, which are relative to the current working directory.
+func newPathFilter(exclude, include []string, noIgnore bool) (pathFilter, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return pathFilter{}, err
+	}
+
+	filter := pathFilter{noIgnore: noIgnore}
+
+	for _, line := range exclude {
+		if pattern, ok := parseIgnorePattern(cwd, line); ok {
+			filter.exclude = append(filter.exclude, pattern)
+		}
+	}
+
+	for _, line := range include {
+		if pattern, ok := parseIgnorePattern(cwd, line); ok {
+			filter.include = append(filter.include, pattern)
+		}
+	}
+
+	return filter, nil
+}
+
+// rules returns the ignore rules that apply when walking the given absolute
+// directory, within the given repository root. These are the rules from
+// `.git/info/exclude`, and from any `.gitignore` files between the repository
+// root and the given directory. Rules from `.gitignore` files beneath the
+// directory are added as they are walked.
+func (f pathFilter) rules(root, dir string) (ignoreRules, error) {
+	if f.noIgnore {
+		return nil, nil
+	}
+
+	var rules ignoreRules
+
+	if exclude := excludeFile(root); exclude != "" {
+		var err error
+		if rules, err = rules.load(root, exclude); err != nil {
+			return nil, err
+		}
+	}
+
+	// Collect every directory from the repository root down to (but not
+	// including) the given directory.
+	var parents []string
+	for parent := dir; parent != root; {
+		parent = filepath.Dir(parent)
+		parents = append(parents, parent)
+	}
+
+	slices.Reverse(parents)
+
+	for _, parent := range parents {
+		var err error
+		if rules, err = rules.load(parent, filepath.Join(parent, ".gitignore")); err != nil {
+			return nil, err
+		}
+	}
+
+	return rules, nil
+}
+
+// excludeFile returns the name of the `info/exclude` file for the repository
+// with the given root directory. In worktrees and submodules, `.git` is a file
+// pointing to the actual git directory, which is found by asking git. Returns
+// an empty string if the git directory could not be found.
+func excludeFile(root string) string {
+	dir := filepath.Join(root, ".git")
+
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return filepath.Join(dir, "info", "exclude")
+	}
+
+	output, err := git(nil, "-C", root, "rev-parse", "--path-format=absolute", "--git-path", "info/exclude")
+	if err != nil {
+		return ""
+	}
+
+	return filepath.FromSlash(strings.TrimSpace(string(output)))
+}
+
+// findRepository searches the given absolute directory, and each of its
+// parent directories, for the root of a git repository. Returns an empty
+// string if none was found.
+func findRepository(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Reached the filesystem root.
+			return ""
+		}
+
+		dir = parent
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchSegments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "a/b", path: "a/b", expected: true},
+		{pattern: "a/b", path: "a/b/c"},
+		{pattern: "a/b", path: "x/a/b"},
+		{pattern: "a/*", path: "a/b", expected: true},
+		{pattern: "a/*", path: "a/b/c"},
+		{pattern: "a/b?", path: "a/bc", expected: true},
+		{pattern: "a/[bc]", path: "a/c", expected: true},
+		{pattern: "**/b", path: "b", expected: true},
+		{pattern: "**/b", path: "a/b", expected: true},
+		{pattern: "**/b", path: "a/x/b", expected: true},
+		{pattern: "**/b", path: "a/b/c"},
+		{pattern: "a/**/b", path: "a/b", expected: true},
+		{pattern: "a/**/b", path: "a/x/b", expected: true},
+		{pattern: "a/**/b", path: "a/x/y/b", expected: true},
+		{pattern: "a/**/b", path: "x/a/b"},
+		{pattern: "a/**", path: "a/b", expected: true},
+		{pattern: "a/**", path: "a/b/c", expected: true},
+		{pattern: "a/**", path: "a"},
+		{pattern: "a/**", path: "b/c"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.path, func(t *testing.T) {
+			t.Parallel()
+
+			actual := matchSegments(strings.Split(test.pattern, "/"), strings.Split(test.path, "/"))
+			if actual != test.expected {
+				t.Fatalf("expected %t, actual %t", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	t.Parallel()
+
+	base := filepath.FromSlash("/repo")
+
+	tests := []struct {
+		name     string
+		lines    []string
+		path     string
+		dir      bool
+		expected bool
+	}{
+		{
+			name:  "blank and comments",
+			lines: []string{"", "   ", "# go.mod"},
+			path:  "go.mod",
+		},
+		{
+			name:     "escaped comment",
+			lines:    []string{`\#go.mod`},
+			path:     "#go.mod",
+			expected: true,
+		},
+		{
+			name:     "unanchored",
+			lines:    []string{"go.mod"},
+			path:     "a/b/go.mod",
+			expected: true,
+		},
+		{
+			name:     "unanchored glob",
+			lines:    []string{"*.mod"},
+			path:     "a/go.mod",
+			expected: true,
+		},
+		{
+			name:     "anchored leading slash",
+			lines:    []string{"/go.mod"},
+			path:     "go.mod",
+			expected: true,
+		},
+		{
+			name:  "anchored leading slash nested",
+			lines: []string{"/go.mod"},
+			path:  "a/go.mod",
+		},
+		{
+			name:     "anchored middle slash",
+			lines:    []string{"a/go.mod"},
+			path:     "a/go.mod",
+			expected: true,
+		},
+		{
+			name:  "anchored middle slash nested",
+			lines: []string{"a/go.mod"},
+			path:  "b/a/go.mod",
+		},
+		{
+			name:     "directory only",
+			lines:    []string{"a/"},
+			path:     "b/a",
+			dir:      true,
+			expected: true,
+		},
+		{
+			name:  "directory only file",
+			lines: []string{"a/"},
+			path:  "b/a",
+		},
+		{
+			name:     "double star leading",
+			lines:    []string{"**/testdata"},
+			path:     "a/b/testdata",
+			dir:      true,
+			expected: true,
+		},
+		{
+			name:     "double star middle",
+			lines:    []string{"a/**/go.mod"},
+			path:     "a/go.mod",
+			expected: true,
+		},
+		{
+			name:     "double star trailing",
+			lines:    []string{"a/**"},
+			path:     "a/b/go.mod",
+			expected: true,
+		},
+		{
+			name:  "double star trailing directory",
+			lines: []string{"a/**"},
+			path:  "a",
+			dir:   true,
+		},
+		{
+			name:  "negated",
+			lines: []string{"*.mod", "!go.mod"},
+			path:  "a/go.mod",
+		},
+		{
+			name:     "negated then ignored",
+			lines:    []string{"!go.mod", "*.mod"},
+			path:     "a/go.mod",
+			expected: true,
+		},
+		{
+			name:     "escaped negation",
+			lines:    []string{`\!go.mod`},
+			path:     "!go.mod",
+			expected: true,
+		},
+		{
+			name:  "outside base",
+			lines: []string{"*"},
+			path:  "../go.mod",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rules ignoreRules
+
+			for _, line := range test.lines {
+				if pattern, ok := parseIgnorePattern(base, line); ok {
+					rules = append(rules, pattern)
+				}
+			}
+
+			actual := rules.ignored(filepath.Join(base, filepath.FromSlash(test.path)), test.dir)
+			if actual != test.expected {
+				t.Fatalf("expected %t, actual %t", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		filter   pathFilter
+		expected []string
+	}{
+		{
+			name: "gitignore",
+			files: map[string]string{
+				".gitignore":                "/build/\n",
+				"go.mod":                    "",
+				"build/go.mod":              "",
+				"a/build/go.mod":            "",
+				"vendor/example.com/go.mod": "",
+			},
+			expected: []string{"a/build/go.mod", "go.mod"},
+		},
+		{
+			name: "nested gitignore",
+			files: map[string]string{
+				"a/.gitignore": "go.work\n",
+				"a/go.work":    "",
+				"a/b/go.work":  "",
+				"c/go.work":    "",
+			},
+			expected: []string{"c/go.work"},
+		},
+		{
+			name: "negation",
+			files: map[string]string{
+				".gitignore":           "examples/**\n!examples/keep/\n!examples/keep/go.mod\n",
+				"examples/go.mod":      "",
+				"examples/drop/go.mod": "",
+				"examples/keep/go.mod": "",
+			},
+			expected: []string{"examples/keep/go.mod"},
+		},
+		{
+			name: "negation beneath ignored directory",
+			files: map[string]string{
+				".gitignore":           "examples/\n!examples/keep/go.mod\n",
+				"examples/keep/go.mod": "",
+			},
+		},
+		{
+			name: "no ignore",
+			files: map[string]string{
+				".gitignore": "go.mod\n",
+				"go.mod":     "",
+			},
+			filter:   pathFilter{noIgnore: true},
+			expected: []string{"go.mod"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			if err := os.Mkdir(filepath.Join(dir, ".git"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			for name, content := range test.files {
+				filename := filepath.Join(dir, filepath.FromSlash(name))
+
+				if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			paths, err := walk(dir, test.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual := make([]string, 0, len(paths))
+			for _, path := range paths {
+				rel, err := filepath.Rel(dir, path)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				actual = append(actual, filepath.ToSlash(rel))
+			}
+
+			if strings.Join(actual, " ") != strings.Join(test.expected, " ") {
+				t.Fatalf("expected %q, actual %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWalkWorktree(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	worktree := filepath.Join(dir, "worktree")
+
+	commands := [][]string{
+		{"init", "--quiet", repo},
+		{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "--message=initial"}, //nolint:lll
+		{"-C", repo, "worktree", "add", "--quiet", worktree},
+	}
+
+	for _, args := range commands {
+		if _, err := git(nil, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := map[string]string{
+		filepath.Join(repo, ".git", "info", "exclude"): "build/\n",
+		filepath.Join(worktree, "go.mod"):              "",
+		filepath.Join(worktree, "build", "go.mod"):     "",
+		filepath.Join(dir, "dangling", ".git"):         "gitdir: missing\n",
+		filepath.Join(dir, "dangling", "go.mod"):       "",
+	}
+
+	for filename, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		dir      string
+		expected []string
+	}{
+		{
+			// The exclude file is shared with the main repository.
+			name:     "worktree",
+			dir:      worktree,
+			expected: []string{filepath.Join(worktree, "go.mod")},
+		},
+		{
+			// A .git file which does not point to a git directory, as in an
+			// uninitialized submodule, has no exclude file.
+			name:     "dangling git file",
+			dir:      filepath.Join(dir, "dangling"),
+			expected: []string{filepath.Join(dir, "dangling", "go.mod")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := walk(test.dir, pathFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Join(actual, " ") != strings.Join(test.expected, " ") {
+				t.Fatalf("expected %q, actual %q", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+	"github.com/joshdk/modfmt/pkg/modfmt/lint"
+)
+
+const (
+	// ruleFormat is the name used for diagnostics about unformatted files.
+	ruleFormat = "format"
+
+	// ruleCanonical is the name used for diagnostics about non-canonical
+	// versions and invalid module paths.
+	ruleCanonical = "canonical"
+)
+
+// lintCommand returns the command line handler for `modfmt lint`.
+func lintCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint [directory|file]...",
+		Short: "Check go.mod and go.work files against lint rules",
+		Long: "Check go.mod and go.work files against lint rules, and report any files that are unformatted.\n\n" +
+			"Only the rules marked as default are checked unless --rules is given. Policy rules, such as " +
+			"require-toolchain, can be added with --enable.\n\n" +
+			"Built-in rules:\n" + ruleList(),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	// Define --config flag.
+	configFile := cmd.Flags().String(
+		"config",
+		"",
+		"use the given configuration file instead of searching for one")
+
+	// Define --rules flag.
+	cmd.Flags().StringSlice(
+		"rules",
+		nil,
+		"rules to check (default every built-in rule marked as default)")
+
+	// Define --enable flag.
+	cmd.Flags().StringSlice(
+		"enable",
+		nil,
+		"additional rules to check, such as policy rules")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		// If no arguments are given, default to searching through the current
+		// working directory.
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+
+		// Validate any rules given as flags up front, rather than failing
+		// every file individually.
+		var flagConfig config
+		if err := flagConfig.override(cmd.Flags()); err != nil {
+			return err
+		}
+
+		if _, err := flagConfig.rules(); err != nil {
+			return err
+		}
+
+		filter, err := newPathFilter(nil, nil, false)
+		if err != nil {
+			return err
+		}
+
+		filenames, discoverErr := discover(args, filter)
+
+		slices.Sort(filenames)
+		filenames = slices.Compact(filenames)
+
+		resolver := &configResolver{
+			explicit: *configFile,
+			flags:    cmd.Flags(),
+			cache:    make(map[string]config),
+		}
+
+		var (
+			failures []result
+			problems int
+			files    int
+		)
+
+		for _, filename := range filenames {
+			diagnostics, data, err := lintFile(filename, resolver)
+			if err != nil {
+				failures = append(failures, result{filename: filename, original: data, err: err})
+
+				continue
+			}
+
+			for _, diagnostic := range diagnostics {
+				fmt.Fprintln(cmd.OutOrStdout(), diagnostic)
+			}
+
+			if len(diagnostics) > 0 {
+				problems += len(diagnostics)
+				files++
+			}
+		}
+
+		if len(failures) > 0 || discoverErr != nil {
+			// Print every error that occurred, followed by a summary. Parse
+			// errors are printed with an excerpt of the file.
+			for _, err := range unjoin(discoverErr) {
+				fmt.Fprintln(cmd.ErrOrStderr(), "modfmt:", err)
+			}
+
+			for _, res := range failures {
+				printError(cmd.ErrOrStderr(), res.err, res.original)
+			}
+
+			return &ExitError{Code: ExitErrors, Err: summarize("linted", len(filenames), len(failures), 0, discoverErr)}
+		}
+
+		if problems > 0 {
+			return &ExitError{
+				Code: ExitUnformatted,
+				Err:  fmt.Errorf("found %d problems in %d of %d files", problems, files, len(filenames)),
+			}
+		}
+
+		return nil
+	}
+
+	return cmd
+}
+
+// lintFile checks the given file against the configured rules, and reports if
+// the file is unformatted. The contents of the file are also returned, once
+// read, so that errors can be printed with an excerpt of the file.
+func lintFile(filename string, resolver *configResolver) ([]lint.Diagnostic, []byte, error) {
+	if filename == "-" {
+		return nil, nil, errors.New("cannot lint standard input")
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, _, err := resolver.resolve(filename)
+	if err != nil {
+		return nil, data, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	rules, err := cfg.rules()
+	if err != nil {
+		return nil, data, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	diagnostics, err := lint.Lint(filename, data, rules...)
+	if err != nil {
+		return nil, data, err
+	}
+
+	formatted, err := modfmt.FormatWithOptions(filename, data, cfg.options())
+	if err != nil {
+		return nil, data, err
+	}
+
+	if !bytes.Equal(data, formatted) {
+		diagnostics = append(diagnostics, lint.Diagnostic{
+			Diagnostic: modfmt.Diagnostic{
+				Filename: filename,
+				Pos:      diffPosition(data, formatted),
+				Message:  fmt.Sprintf("file is not formatted, run `modfmt -w %s`", filename),
+			},
+			Rule: ruleFormat,
+		})
+	}
+
+	if cfg.Canonical {
+		checked, err := modfmt.Check(filename, data)
+		if err != nil {
+			return nil, data, err
+		}
+
+		for _, diagnostic := range checked {
+			diagnostics = append(diagnostics, lint.Diagnostic{Diagnostic: diagnostic, Rule: ruleCanonical})
+		}
+	}
+
+	slices.SortStableFunc(diagnostics, func(a, b lint.Diagnostic) int {
+		if c := cmp.Compare(a.Pos.Line, b.Pos.Line); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.Pos.LineRune, b.Pos.LineRune)
+	})
+
+	return diagnostics, data, nil
+}
+
+// diffPosition returns the position of the first line that formatting would
+// change.
+func diffPosition(original, formatted []byte) modfile.Position {
+	pos := modfile.Position{Line: 1, LineRune: 1}
+
+	if hunks := diffHunks(original, formatted); len(hunks) > 0 {
+		for _, op := range hunks[0].operations {
+			if op.kind != ' ' {
+				break
+			}
+
+			pos.Line++
+		}
+
+		pos.Line += max(0, hunks[0].oldStart-1)
+	}
+
+	return pos
+}
+
+// ruleList returns a list of every built-in rule name, one per line. Rules
+// which are checked by default are marked as such.
+func ruleList() string {
+	defaults := make(map[string]bool)
+	for _, rule := range lint.DefaultRules() {
+		defaults[rule.Name()] = true
+	}
+
+	var result string
+
+	for _, rule := range lint.Rules() {
+		result += "  " + rule.Name()
+		if defaults[rule.Name()] {
+			result += " (default)"
+		}
+
+		result += "\n"
+	}
+
+	return result
+}
@@ -0,0 +1,41 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintParseError(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "go.mod")
+
+	if err := os.WriteFile(filename, []byte("module example.com/foo/bar\n\nrequire bogus\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := Command()
+	cmd.SetArgs([]string{"lint", filename})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+
+	if err := cmd.Execute(); ExitCode(err) != ExitErrors {
+		t.Fatalf("expected exit code %d, actual %d (%v)", ExitErrors, ExitCode(err), err)
+	}
+
+	// Parse errors are printed with an excerpt of the file.
+	expected := "modfmt: " + filename + ":3: usage: require module/path v1.2.3\n  3 | require bogus\n    | ^\n"
+
+	if stderr.String() != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, stderr.String())
+	}
+}
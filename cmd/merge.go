@@ -0,0 +1,130 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// mergeDriverCommand returns the command line handler for
+// `modfmt merge-driver`.
+func mergeDriverCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge-driver base ours theirs [path]",
+		Short: "Merge go.mod and go.work files as a git merge driver",
+		Long: "Merge go.mod and go.work files as a git merge driver.\n\n" +
+			"Performs a three-way merge of the base, ours, and theirs files, and writes the formatted result to ours. " +
+			"If the files cannot be merged, then falls back to a textual merge with `git merge-file`, leaving conflict " +
+			"markers in ours. The path is the name of the file being merged, and is used to find the configuration.\n\n" +
+			"Register as a merge driver with:\n" +
+			"  git config merge.modfmt.driver \"modfmt merge-driver %O %A %B %P\"\n" +
+			"  echo \"go.mod merge=modfmt\" >> .gitattributes\n" +
+			"  echo \"go.work merge=modfmt\" >> .gitattributes",
+		Args: cobra.RangeArgs(3, 4), //nolint:mnd
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	// Define --config flag.
+	configFile := cmd.Flags().String(
+		"config",
+		"",
+		"use the given configuration file instead of searching for one")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		baseFile, oursFile, theirsFile := args[0], args[1], args[2]
+
+		// The merged files are temporary files, so the path of the file being
+		// merged is used instead when available.
+		filename := oursFile
+		if len(args) > 3 { //nolint:mnd
+			filename = args[3]
+		}
+
+		base, err := os.ReadFile(baseFile)
+		if err != nil {
+			return err
+		}
+
+		ours, err := os.ReadFile(oursFile)
+		if err != nil {
+			return err
+		}
+
+		theirs, err := os.ReadFile(theirsFile)
+		if err != nil {
+			return err
+		}
+
+		resolver := &configResolver{
+			explicit: *configFile,
+			flags:    cmd.Flags(),
+			cache:    make(map[string]config),
+		}
+
+		cfg, _, err := resolver.resolve(filename)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+
+		merged, err := modfmt.Merge(filename, base, ours, theirs, cfg.options())
+		if err == nil {
+			return writeFile(oursFile, merged, ours, "")
+		}
+
+		for _, err := range unjoin(err) {
+			fmt.Fprintln(cmd.ErrOrStderr(), "modfmt:", err)
+		}
+
+		// Fall back to a textual merge, which leaves conflict markers for any
+		// conflicting changes.
+		conflicts, err := mergeFile(cmd.ErrOrStderr(), baseFile, oursFile, theirsFile)
+		switch {
+		case err != nil:
+			return &ExitError{Code: ExitErrors, Err: err}
+		case conflicts > 0:
+			return &ExitError{Code: ExitConflicts, Err: fmt.Errorf("%s: %d conflicts must be resolved by hand", filename, conflicts)} //nolint:lll
+		}
+
+		return nil
+	}
+
+	return cmd
+}
+
+// maxConflicts is the largest number of conflicts reported by
+// `git merge-file`. Larger exit codes indicate an error instead.
+const maxConflicts = 127
+
+// mergeFile performs a textual three-way merge with `git merge-file`, writing
+// the result to ours. Any messages from git are written to the given writer.
+// Returns the number of conflicts left in ours, up to a maximum of 127.
+func mergeFile(stderr io.Writer, base, ours, theirs string) (int, error) {
+	cmd := exec.Command("git", "merge-file", "-L", "ours", "-L", "base", "-L", "theirs", ours, base, theirs)
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() <= maxConflicts {
+		return exitErr.ExitCode(), nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("git merge-file: %w", err)
+	}
+
+	return 0, nil
+}
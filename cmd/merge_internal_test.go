@@ -0,0 +1,103 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		missing   bool
+		conflicts int
+		expected  string
+		err       string
+	}{
+		{
+			name:     "clean",
+			base:     "a\nb\nc\n",
+			ours:     "A\nb\nc\n",
+			theirs:   "a\nb\nC\n",
+			expected: "A\nb\nC\n",
+		},
+		{
+			name:      "conflict",
+			base:      "a\n",
+			ours:      "b\n",
+			theirs:    "c\n",
+			conflicts: 1,
+			expected:  "<<<<<<< ours\nb\n=======\nc\n>>>>>>> theirs\n",
+		},
+		{
+			name:     "missing base",
+			ours:     "b\n",
+			theirs:   "c\n",
+			missing:  true,
+			expected: "b\n",
+			err:      "git merge-file: exit status 255",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			files := map[string]string{"ours": test.ours, "theirs": test.theirs}
+			if !test.missing {
+				files["base"] = test.base
+			}
+
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var stderr bytes.Buffer
+
+			conflicts, err := mergeFile(&stderr, filepath.Join(dir, "base"), filepath.Join(dir, "ours"), filepath.Join(dir, "theirs")) //nolint:lll
+
+			switch {
+			case test.err != "" && err == nil:
+				t.Fatal("expected an error")
+			case test.err != "" && err.Error() != test.err:
+				t.Fatalf("expected error %q, actual %q", test.err, err.Error())
+			case test.err == "" && err != nil:
+				t.Fatal(err)
+			}
+
+			// Errors from git are written to the given writer, rather than
+			// directly to standard error.
+			if (stderr.Len() > 0) != test.missing {
+				t.Fatalf("unexpected messages from git:\n%s", stderr.String())
+			}
+
+			if conflicts != test.conflicts {
+				t.Fatalf("expected %d conflicts, actual %d", test.conflicts, conflicts)
+			}
+
+			actual, err := os.ReadFile(filepath.Join(dir, "ours"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(actual) != test.expected {
+				t.Fatalf("expected:\n%s\nactual:\n%s", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshdk/buildversion"
+	"golang.org/x/mod/modfile"
+)
+
+const (
+	// outputText is the default human-readable output format.
+	outputText = "text"
+
+	// outputJSON is the JSON output format.
+	outputJSON = "json"
+
+	// outputSARIF is the SARIF output format, suitable for uploading to code
+	// scanning services.
+	outputSARIF = "sarif"
+)
+
+// jsonReport is the top-level structure of the JSON output format.
+type jsonReport struct {
+	Files []jsonFile `json:"files"`
+}
+
+// jsonFile describes the outcome of formatting a single file.
+type jsonFile struct {
+	// Path is the name of the file.
+	Path string `json:"path"`
+
+	// Formatted is true if the file was already formatted.
+	Formatted bool `json:"formatted"`
+
+	// Errors are any errors that occurred while reading, formatting, or
+	// writing the file.
+	Errors []jsonError `json:"errors,omitempty"`
+
+	// Hunks are the changes needed to format the file.
+	Hunks []jsonHunk `json:"hunks,omitempty"`
+
+	// Diagnostics are any non-canonical versions or invalid module paths
+	// found in the file.
+	Diagnostics []jsonError `json:"diagnostics,omitempty"`
+}
+
+// jsonError describes a single error, along with its position if known.
+type jsonError struct {
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// jsonHunk describes a single contiguous region of changes.
+type jsonHunk struct {
+	OldStart int      `json:"old_start"`
+	OldLines int      `json:"old_lines"`
+	NewStart int      `json:"new_start"`
+	NewLines int      `json:"new_lines"`
+	Lines    []string `json:"lines"`
+}
+
+// reportJSON writes the given results in the JSON output format.
+func reportJSON(w io.Writer, results []result) error {
+	report := jsonReport{
+		Files: make([]jsonFile, 0, len(results)),
+	}
+
+	for _, res := range results {
+		file := jsonFile{
+			Path:      res.filename,
+			Formatted: res.err == nil && !res.unformatted(),
+			Errors:    errorDetails(res.err),
+		}
+
+		if res.unformatted() {
+			file.Hunks = jsonHunks(diffHunks(res.original, res.formatted))
+		}
+
+		for _, diagnostic := range res.diagnostics {
+			file.Diagnostics = append(file.Diagnostics, jsonError{
+				Message: diagnostic.Message,
+				Line:    diagnostic.Pos.Line,
+				Column:  diagnostic.Pos.LineRune,
+			})
+		}
+
+		report.Files = append(report.Files, file)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+// jsonHunks converts the given hunks into their JSON output form. Each line is
+// prefixed with its operation, and stripped of its trailing newline.
+func jsonHunks(hunks []hunk) []jsonHunk {
+	results := make([]jsonHunk, 0, len(hunks))
+
+	for _, h := range hunks {
+		lines := make([]string, 0, len(h.operations))
+		for _, op := range h.operations {
+			lines = append(lines, string(op.kind)+strings.TrimSuffix(op.line, "\n"))
+		}
+
+		results = append(results, jsonHunk{
+			OldStart: h.oldStart,
+			OldLines: h.oldLines,
+			NewStart: h.newStart,
+			NewLines: h.newLines,
+			Lines:    lines,
+		})
+	}
+
+	return results
+}
+
+// errorDetails splits the given error into individual errors, extracting
+// positions from any modfile parse errors. Returns nil if the error is nil.
+func errorDetails(err error) []jsonError {
+	if err == nil {
+		return nil
+	}
+
+	var list modfile.ErrorList
+	if !errors.As(err, &list) {
+		return []jsonError{{Message: err.Error()}}
+	}
+
+	details := make([]jsonError, 0, len(list))
+
+	for _, e := range list {
+		message := e.Err.Error()
+		if e.ModPath != "" {
+			message = fmt.Sprintf("%s %s: %s", e.Verb, e.ModPath, message)
+		}
+
+		details = append(details, jsonError{
+			Message: message,
+			Line:    e.Pos.Line,
+			Column:  e.Pos.LineRune,
+		})
+	}
+
+	return details
+}
+
+// sarifLog is the top-level structure of the SARIF output format.
+//
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+}
+
+const (
+	// sarifRuleUnformatted identifies results for unformatted files.
+	sarifRuleUnformatted = "unformatted"
+
+	// sarifRuleError identifies results for files which could not be read,
+	// parsed, or written.
+	sarifRuleError = "error"
+
+	// sarifRuleCanonical identifies results for non-canonical versions and
+	// invalid module paths.
+	sarifRuleCanonical = "canonical"
+)
+
+// reportSARIF writes the given results in the SARIF output format. Each hunk
+// of an unformatted file, and each error, is reported as a separate result.
+func reportSARIF(w io.Writer, results []result) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "modfmt",
+				Version:        buildversion.Template("{{ .Version }}"),
+				InformationURI: "https://github.com/joshdk/modfmt",
+				Rules: []sarifRule{
+					{ID: sarifRuleUnformatted, ShortDescription: sarifMessage{Text: "File is not formatted"}},
+					{ID: sarifRuleError, ShortDescription: sarifMessage{Text: "File could not be formatted"}},
+					{ID: sarifRuleCanonical, ShortDescription: sarifMessage{Text: "Module version or path is not canonical"}},
+				},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	for _, res := range results {
+		uri := filepath.ToSlash(res.filename)
+
+		for _, detail := range errorDetails(res.err) {
+			var region *sarifRegion
+			if detail.Line > 0 {
+				region = &sarifRegion{StartLine: detail.Line, StartColumn: detail.Column}
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    sarifRuleError,
+				Level:     "error",
+				Message:   sarifMessage{Text: detail.Message},
+				Locations: []sarifLocation{sarifLocationFor(uri, region)},
+			})
+		}
+
+		for _, diagnostic := range res.diagnostics {
+			region := &sarifRegion{StartLine: diagnostic.Pos.Line, StartColumn: diagnostic.Pos.LineRune}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    sarifRuleCanonical,
+				Level:     "warning",
+				Message:   sarifMessage{Text: diagnostic.Message},
+				Locations: []sarifLocation{sarifLocationFor(uri, region)},
+			})
+		}
+
+		if !res.unformatted() {
+			continue
+		}
+
+		message := fmt.Sprintf("%s is not formatted, run `modfmt -w %s`", filepath.Base(res.filename), res.filename)
+
+		for _, h := range diffHunks(res.original, res.formatted) {
+			// Hunks which only insert lines refer to the line before the
+			// insertion, which may be the start of the file.
+			region := &sarifRegion{StartLine: max(1, h.oldStart)}
+			region.EndLine = max(region.StartLine, h.oldStart+h.oldLines-1)
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    sarifRuleUnformatted,
+				Level:     "warning",
+				Message:   sarifMessage{Text: message},
+				Locations: []sarifLocation{sarifLocationFor(uri, region)},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifLocationFor returns a location for the given file URI and region.
+func sarifLocationFor(uri string, region *sarifRegion) sarifLocation {
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: uri},
+			Region:           region,
+		},
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// reportResults are the results written by each of the report tests.
+var reportResults = []result{
+	{
+		filename:  "a/go.mod",
+		original:  []byte("module example.com/a\n"),
+		formatted: []byte("module example.com/a\n"),
+	},
+	{
+		filename:  "b/go.mod",
+		original:  []byte("go 1.23.0\n"),
+		formatted: []byte("module example.com/b\n\ngo 1.23.0\n"),
+	},
+	{
+		filename:  "c/go.mod",
+		formatted: []byte("module example.com/c\n"),
+	},
+	{
+		filename: "d/go.mod",
+		original: []byte("module example.com/d\nfoo\n"),
+		err: modfile.ErrorList{{
+			Filename: "d/go.mod",
+			Pos:      modfile.Position{Line: 2, LineRune: 1},
+			Err:      errors.New("unknown directive: foo"),
+		}},
+	},
+	{
+		filename:  "e/go.mod",
+		original:  []byte("module example.com/e\n"),
+		formatted: []byte("module example.com/e\n"),
+		diagnostics: []modfmt.Diagnostic{{
+			Pos:     modfile.Position{Line: 1, LineRune: 8},
+			Message: "example.com/e: bad module path",
+		}},
+	},
+}
+
+func TestReportJSON(t *testing.T) {
+	t.Parallel()
+
+	const expected = `{
+  "files": [
+    {
+      "path": "a/go.mod",
+      "formatted": true
+    },
+    {
+      "path": "b/go.mod",
+      "formatted": false,
+      "hunks": [
+        {
+          "old_start": 1,
+          "old_lines": 1,
+          "new_start": 1,
+          "new_lines": 3,
+          "lines": [
+            "+module example.com/b",
+            "+",
+            " go 1.23.0"
+          ]
+        }
+      ]
+    },
+    {
+      "path": "c/go.mod",
+      "formatted": false,
+      "hunks": [
+        {
+          "old_start": 1,
+          "old_lines": 0,
+          "new_start": 1,
+          "new_lines": 1,
+          "lines": [
+            "+module example.com/c"
+          ]
+        }
+      ]
+    },
+    {
+      "path": "d/go.mod",
+      "formatted": false,
+      "errors": [
+        {
+          "message": "unknown directive: foo",
+          "line": 2,
+          "column": 1
+        }
+      ]
+    },
+    {
+      "path": "e/go.mod",
+      "formatted": true,
+      "diagnostics": [
+        {
+          "message": "example.com/e: bad module path",
+          "line": 1,
+          "column": 8
+        }
+      ]
+    }
+  ]
+}
+`
+
+	var buf bytes.Buffer
+	if err := reportJSON(&buf, reportResults); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+}
+
+func TestReportSARIF(t *testing.T) {
+	t.Parallel()
+
+	expected := []string{
+		"b/go.mod:1-1 unformatted: go.mod is not formatted, run `modfmt -w b/go.mod`",
+		"c/go.mod:1-1 unformatted: go.mod is not formatted, run `modfmt -w c/go.mod`",
+		"d/go.mod:2-0 error: unknown directive: foo",
+		"e/go.mod:1-0 canonical: example.com/e: bad module path",
+	}
+
+	var buf bytes.Buffer
+	if err := reportSARIF(&buf, reportResults); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(log.Runs) != 1 {
+		t.Fatalf("expected 1 run, actual %d", len(log.Runs))
+	}
+
+	actual := make([]string, 0, len(log.Runs[0].Results))
+
+	for _, res := range log.Runs[0].Results {
+		location := res.Locations[0].PhysicalLocation
+		if location.Region.EndLine != 0 && location.Region.EndLine < location.Region.StartLine {
+			t.Errorf("%s: end line %d is before start line %d",
+				location.ArtifactLocation.URI, location.Region.EndLine, location.Region.StartLine)
+		}
+
+		actual = append(actual, fmt.Sprintf("%s:%d-%d %s: %s",
+			location.ArtifactLocation.URI, location.Region.StartLine, location.Region.EndLine,
+			res.RuleID, res.Message.Text))
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected:\n%s\nactual:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/mod/modfile"
+)
+
+// printError prints the given error for a file with the given contents. Each
+// modfile error is printed along with an excerpt of the line that it refers
+// to, with a caret marking its column.
+func printError(w io.Writer, err error, source []byte) {
+	var list modfile.ErrorList
+	if !errors.As(err, &list) {
+		fmt.Fprintln(w, "modfmt:", err)
+
+		return
+	}
+
+	for _, e := range list {
+		fmt.Fprintln(w, "modfmt:", e.Error())
+		fmt.Fprint(w, snippet(source, e.Pos))
+	}
+}
+
+// snippet returns the line from the given contents at the given position,
+// followed by a caret marking the column. If the position has no column, then
+// the first non-blank character in the line is marked instead. Returns an
+// empty string if the position is not within the contents.
+//
+//	3 | require example.com/foo/bar
+//	  |         ^
+func snippet(source []byte, pos modfile.Position) string {
+	lines := strings.Split(string(source), "\n")
+	if pos.Line < 1 || pos.Line > len(lines) {
+		return ""
+	}
+
+	line := []rune(strings.TrimRight(lines[pos.Line-1], "\r"))
+
+	column := pos.LineRune - 1
+	if column < 0 {
+		column = max(0, slices.IndexFunc(line, func(r rune) bool { return !unicode.IsSpace(r) }))
+	}
+
+	// Keep any tabs before the column, so that the caret lines up with the
+	// line above it.
+	var marker strings.Builder
+	for _, r := range line[:min(column, len(line))] {
+		if r == '\t' {
+			marker.WriteRune('\t')
+		} else {
+			marker.WriteRune(' ')
+		}
+	}
+
+	number := strconv.Itoa(pos.Line)
+	gutter := strings.Repeat(" ", len(number))
+
+	return fmt.Sprintf("  %s | %s\n  %s | %s^\n", number, string(line), gutter, marker.String())
+}
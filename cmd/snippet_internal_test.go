@@ -0,0 +1,167 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+func TestSnippet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		source   string
+		pos      modfile.Position
+		expected string
+	}{
+		{
+			name:     "column",
+			source:   "module example.com/foo/bar\nrequire bogus\n",
+			pos:      modfile.Position{Line: 2, LineRune: 9},
+			expected: "  2 | require bogus\n    |         ^\n",
+		},
+		{
+			name:     "tabs",
+			source:   "require (\n\tbogus v1\n)\n",
+			pos:      modfile.Position{Line: 2, LineRune: 8},
+			expected: "  2 | \tbogus v1\n    | \t      ^\n",
+		},
+		{
+			name:     "tabs after column",
+			source:   "require\tbogus\tv1\n",
+			pos:      modfile.Position{Line: 1, LineRune: 1},
+			expected: "  1 | require\tbogus\tv1\n    | ^\n",
+		},
+		{
+			name:     "no column",
+			source:   "require (\n\t  bogus\n)\n",
+			pos:      modfile.Position{Line: 2},
+			expected: "  2 | \t  bogus\n    | \t  ^\n",
+		},
+		{
+			name:     "no column blank line",
+			source:   "module example.com/foo/bar\n\t\n",
+			pos:      modfile.Position{Line: 2},
+			expected: "  2 | \t\n    | ^\n",
+		},
+		{
+			name:     "column past end of line",
+			source:   "bogus\n",
+			pos:      modfile.Position{Line: 1, LineRune: 10},
+			expected: "  1 | bogus\n    |      ^\n",
+		},
+		{
+			name:     "multibyte",
+			source:   "module example.com/é bogus\n",
+			pos:      modfile.Position{Line: 1, LineRune: 22},
+			expected: "  1 | module example.com/é bogus\n    |                      ^\n",
+		},
+		{
+			name:     "crlf",
+			source:   "module example.com/foo/bar\r\nrequire bogus\r\n",
+			pos:      modfile.Position{Line: 2, LineRune: 9},
+			expected: "  2 | require bogus\n    |         ^\n",
+		},
+		{
+			name:     "wide line number",
+			source:   strings.Repeat("\n", 9) + "bogus\n",
+			pos:      modfile.Position{Line: 10, LineRune: 1},
+			expected: "  10 | bogus\n     | ^\n",
+		},
+		{
+			name:     "end of file",
+			source:   "module example.com/foo/bar\n",
+			pos:      modfile.Position{Line: 2, LineRune: 1},
+			expected: "  2 | \n    | ^\n",
+		},
+		{
+			name:   "past end of file",
+			source: "module example.com/foo/bar\n",
+			pos:    modfile.Position{Line: 3, LineRune: 1},
+		},
+		{
+			name:   "no line",
+			source: "module example.com/foo/bar\n",
+			pos:    modfile.Position{LineRune: 1},
+		},
+		{
+			name: "empty source",
+			pos:  modfile.Position{Line: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := snippet([]byte(test.source), test.pos); actual != test.expected {
+				t.Fatalf("expected:\n%q\nactual:\n%q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	t.Parallel()
+
+	source := "module example.com/foo/bar\n\nrequire bogus\n"
+
+	_, parseErr := modfmt.Format("go.mod", []byte(source))
+	if parseErr == nil {
+		t.Fatal("expected an error")
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		source   string
+		expected string
+	}{
+		{
+			name:     "plain error",
+			err:      errors.New("go.mod: bogus"),
+			source:   source,
+			expected: "modfmt: go.mod: bogus\n",
+		},
+		{
+			name:     "parse error",
+			err:      parseErr,
+			source:   source,
+			expected: "modfmt: go.mod:3: usage: require module/path v1.2.3\n  3 | require bogus\n    | ^\n",
+		},
+		{
+			name: "multiple errors",
+			err: modfile.ErrorList{
+				{Filename: "go.mod", Pos: modfile.Position{Line: 1, LineRune: 8}, Err: errors.New("first")},
+				{Filename: "go.mod", Pos: modfile.Position{Line: 9, LineRune: 1}, Err: errors.New("second")},
+			},
+			source:   source,
+			expected: "modfmt: go.mod:1:8: first\n  1 | module example.com/foo/bar\n    |        ^\nmodfmt: go.mod:9: second\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			printError(&buf, test.err, []byte(test.source))
+
+			if buf.String() != test.expected {
+				t.Fatalf("expected:\n%s\nactual:\n%s", test.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// writeFile safely replaces the contents of the given file, preserving its
+// mode and ownership. If a backup suffix is given, then the original contents
+// are also written to a file with that suffix.
+func writeFile(filename string, formatted, original []byte, backup string) error {
+	// Resolve any symlinks, so that the link itself is not replaced.
+	target, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	if backup != "" {
+		if err := writeAtomic(target+backup, original, info); err != nil {
+			return err
+		}
+	}
+
+	return writeAtomic(target, formatted, info)
+}
+
+// writeAtomic writes the given data to a temporary file in the same directory
+// as the given file, and then renames it over the given file. The given file
+// info is used to set the mode and ownership of the new file. A crash at any
+// point leaves either the old or the new contents in place, but never a
+// partially written file.
+func writeAtomic(filename string, data []byte, info fs.FileInfo) (err error) {
+	temp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	// Clean up the temporary file if anything goes wrong.
+	defer func() {
+		if err != nil {
+			temp.Close()           //nolint:errcheck
+			os.Remove(temp.Name()) //nolint:errcheck
+		}
+	}()
+
+	if _, err := temp.Write(data); err != nil {
+		return err
+	}
+
+	// Ensure the contents are on disk before the rename makes them visible.
+	if err := temp.Sync(); err != nil {
+		return err
+	}
+
+	if err := chown(temp, info); err != nil {
+		return err
+	}
+
+	// Set the mode after the owner, since changing the owner clears any
+	// setuid and setgid bits.
+	if err := temp.Chmod(info.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)); err != nil {
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temp.Name(), filename); err != nil {
+		return err
+	}
+
+	// Ensure the rename itself is on disk.
+	return syncDir(filepath.Dir(filename))
+}
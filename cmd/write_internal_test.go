@@ -0,0 +1,94 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+//go:build unix
+
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		mode   fs.FileMode
+		backup string
+	}{
+		{
+			name: "mode",
+			mode: 0o640,
+		},
+		{
+			name: "setuid",
+			mode: 0o755 | fs.ModeSetuid,
+		},
+		{
+			name:   "backup",
+			mode:   0o600,
+			backup: ".orig",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.Join(t.TempDir(), "go.mod")
+
+			if err := os.WriteFile(filename, []byte("original"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.Chmod(filename, test.mode); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := writeFile(filename, []byte("formatted"), []byte("original"), test.backup); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := map[string]string{filename: "formatted"}
+			if test.backup != "" {
+				expected[filename+test.backup] = "original"
+			}
+
+			for name, content := range expected {
+				data, err := os.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(data) != content {
+					t.Fatalf("%s: expected %q, actual %q", name, content, data)
+				}
+
+				info, err := os.Stat(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if info.Mode() != test.mode {
+					t.Fatalf("%s: expected mode %v, actual %v", name, test.mode, info.Mode())
+				}
+			}
+
+			// No temporary files are left behind.
+			entries, err := os.ReadDir(filepath.Dir(filename))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(entries) != len(expected) {
+				t.Fatalf("expected %d files, actual %d", len(expected), len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+//go:build !unix
+
+package cmd
+
+import (
+	"io/fs"
+	"os"
+)
+
+// chown is a no-op on platforms without unix file ownership.
+func chown(*os.File, fs.FileInfo) error {
+	return nil
+}
+
+// syncDir is a no-op on platforms where directories cannot be synced.
+func syncDir(string) error {
+	return nil
+}
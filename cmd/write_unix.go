@@ -0,0 +1,48 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+//go:build unix
+
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// chown sets the owner and group of the given file to match the given file
+// info. Permission errors are ignored, since only privileged users can give
+// away ownership of a file.
+func chown(file *os.File, info fs.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	if err := file.Chown(int(stat.Uid), int(stat.Gid)); err != nil && !errors.Is(err, fs.ErrPermission) {
+		return err
+	}
+
+	return nil
+}
+
+// syncDir flushes the given directory to disk, so that any renames within it
+// are durable.
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close() //nolint:errcheck
+
+		return err
+	}
+
+	return file.Close()
+}
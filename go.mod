@@ -3,13 +3,15 @@ module github.com/joshdk/modfmt
 go 1.25.0
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/joshdk/buildversion v0.1.0
 	github.com/joshdk/modfmt/pkg/modfmt v0.0.0-20251025120812-f9988d25d83e
 	github.com/spf13/cobra v1.10.1
+	github.com/spf13/pflag v1.0.10
+	golang.org/x/mod v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.10 // indirect
-	golang.org/x/mod v0.29.0 // indirect
 )
@@ -16,6 +16,6 @@ import (
 func main() {
 	if err := cmd.Command().Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, "modfmt:", err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,168 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+// Package analyzer provides an analysis.Analyzer which reports unformatted
+// `go.mod` and `go.work` files.
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// Analyzer reports any `go.mod` or `go.work` file enclosing the analyzed
+// package that is not formatted. Each diagnostic carries a suggested fix that
+// replaces the entire file with its formatted contents.
+//
+// Files are read through analysis.Pass.ReadFile when the driver allows it,
+// such as when an overlay holds unsaved changes, and are otherwise read from
+// disk. Standard drivers only allow reading the files of a package, which
+// never include the `go.mod` or `go.work` files.
+//
+// Since analyzers are run once per package, every package belonging to the
+// same module will report the same diagnostic. Drivers such as golangci-lint
+// and gopls deduplicate these.
+var Analyzer = &analysis.Analyzer{
+	Name: "modfmt",
+	Doc:  "reports unformatted go.mod and go.work files",
+	URL:  "https://github.com/joshdk/modfmt",
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	// Packages without any files (e.g. only containing test files that were
+	// excluded) have no directory to start searching from.
+	if len(pass.Files) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	// Search upwards from the directory containing the package.
+	dir := filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name())
+
+	for _, name := range []string{"go.mod", "go.work"} {
+		filename, err := find(dir, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if filename == "" {
+			continue
+		}
+
+		if err := check(pass, filename); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+// find searches the given directory, and each of its parent directories, for
+// a file with the given name. Returns an empty string if no file was found.
+func find(dir, name string) (string, error) {
+	for {
+		filename := filepath.Join(dir, name)
+
+		_, err := os.Stat(filename)
+		switch {
+		case err == nil:
+			// Found the file!
+			return filename, nil
+
+		case !errors.Is(err, fs.ErrNotExist):
+			// There was an actual error.
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Reached the filesystem root.
+			return "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// check formats the given file and reports a diagnostic, along with a
+// suggested fix, if the file was unformatted.
+func check(pass *analysis.Pass, filename string) error {
+	original, err := readFile(pass, filename)
+	if err != nil {
+		return err
+	}
+
+	formatted, err := modfmt.Format(filename, original)
+	if err != nil {
+		return err
+	}
+
+	// Did formatting change the file or was it already formatted?
+	if bytes.Equal(original, formatted) {
+		return nil
+	}
+
+	file := tokenFile(pass.Fset, filename, original)
+
+	pass.Report(analysis.Diagnostic{
+		Pos:     file.Pos(0),
+		End:     file.Pos(len(original)),
+		Message: fmt.Sprintf("%s is not formatted", filepath.Base(filename)),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "Format " + filepath.Base(filename),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     file.Pos(0),
+				End:     file.Pos(len(original)),
+				NewText: formatted,
+			}},
+		}},
+	})
+
+	return nil
+}
+
+// readFile returns the contents of the given file, read through the driver if
+// it allows the file to be read, and otherwise read from disk.
+func readFile(pass *analysis.Pass, filename string) ([]byte, error) {
+	if pass.ReadFile != nil {
+		if data, err := pass.ReadFile(filename); err == nil {
+			return data, nil
+		}
+	}
+
+	return os.ReadFile(filename)
+}
+
+// tokenFile returns the file from the given file set with the given name and
+// contents, so that positions inside of it can be reported. The file is added
+// to the file set if it was not already added, such as by another pass over a
+// package in the same module.
+func tokenFile(fset *token.FileSet, filename string, content []byte) *token.File {
+	var file *token.File
+
+	fset.Iterate(func(f *token.File) bool {
+		if f.Name() == filename && f.Size() == len(content) {
+			file = f
+		}
+
+		return file == nil
+	})
+
+	if file == nil {
+		file = fset.AddFile(filename, -1, len(content))
+		file.SetLinesForContent(content)
+	}
+
+	return file
+}
@@ -0,0 +1,138 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package analyzer_test
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/joshdk/modfmt/pkg/modfmt/analyzer"
+)
+
+const testdataDir = "./testdata/example"
+
+func TestAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, filepath.Join(testdataDir, "example.go"), nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var diagnostics []analysis.Diagnostic
+
+	pass := &analysis.Pass{
+		Analyzer: analyzer.Analyzer,
+		Fset:     fset,
+		Files:    []*ast.File{file},
+		ReadFile: os.ReadFile,
+		Report: func(diagnostic analysis.Diagnostic) {
+			diagnostics = append(diagnostics, diagnostic)
+		},
+	}
+
+	if _, err := analyzer.Analyzer.Run(pass); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the go.mod file is unformatted, the go.work file is not.
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diagnostics))
+	}
+
+	if filename := fset.Position(diagnostics[0].Pos).Filename; filename != filepath.Join(testdataDir, "go.mod") {
+		t.Fatalf("expected diagnostic for go.mod, got %s", filename)
+	}
+
+	expectedData, err := os.ReadFile(filepath.Join(testdataDir, "go.mod.formatted"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edits := diagnostics[0].SuggestedFixes[0].TextEdits
+	if len(edits) != 1 || !bytes.Equal(edits[0].NewText, expectedData) {
+		t.Fatal("suggested fix differed from go.mod.formatted")
+	}
+}
+
+func TestAnalyzerRestricted(t *testing.T) {
+	t.Parallel()
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, filepath.Join(testdataDir, "example.go"), nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var diagnostics []analysis.Diagnostic
+
+	pass := &analysis.Pass{
+		Analyzer: analyzer.Analyzer,
+		Fset:     fset,
+		Files:    []*ast.File{file},
+		ReadFile: func(filename string) ([]byte, error) {
+			return nil, fmt.Errorf("Pass.ReadFile: %s is not among OtherFiles, IgnoredFiles, or names of Files", filename)
+		},
+		Report: func(diagnostic analysis.Diagnostic) {
+			diagnostics = append(diagnostics, diagnostic)
+		},
+	}
+
+	if _, err := analyzer.Analyzer.Run(pass); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files which the driver does not allow to be read are read from disk.
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diagnostics))
+	}
+}
+
+func TestAnalyzerChecker(t *testing.T) {
+	t.Parallel()
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax, Dir: testdataDir}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph, err := checker.Analyze([]*analysis.Analyzer{analyzer.Analyzer}, pkgs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+
+	for action := range graph.All() {
+		if action.Err != nil {
+			t.Fatal(action.Err)
+		}
+
+		for _, diagnostic := range action.Diagnostics {
+			position := action.Package.Fset.Position(diagnostic.Pos)
+			messages = append(messages, fmt.Sprintf("%s:%d: %s", filepath.Base(position.Filename), position.Line, diagnostic.Message))
+		}
+	}
+
+	expected := []string{"go.mod:1: go.mod is not formatted"}
+	if !slices.Equal(expected, messages) {
+		t.Fatalf("expected %q, actual %q", expected, messages)
+	}
+}
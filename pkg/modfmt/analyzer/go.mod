@@ -0,0 +1,19 @@
+module github.com/joshdk/modfmt/pkg/modfmt/analyzer
+
+go 1.24.0
+
+require (
+	github.com/joshdk/modfmt/pkg/modfmt v0.0.0-20251025120812-f9988d25d83e
+	golang.org/x/tools v0.38.0
+)
+
+require (
+	golang.org/x/mod v0.29.0 // indirect
+	golang.org/x/sync v0.17.0 // indirect
+)
+
+replace (
+	// The analyzer is developed alongside modfmt in the same workspace, and is
+	// built against the modfmt module in the parent directory.
+	github.com/joshdk/modfmt/pkg/modfmt v0.0.0-20251025120812-f9988d25d83e => ../
+)
@@ -0,0 +1,2 @@
+// Package example is used for testing the modfmt analyzer.
+package example
@@ -0,0 +1,4 @@
+module example.com/foo/bar
+go 1.23.0
+require example.com/b/b v1.2.2
+require example.com/a/a v1.1.1
@@ -0,0 +1,257 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// Diagnostic describes a problem found at a specific position within a
+// `go.mod` or `go.work` file.
+type Diagnostic struct {
+	// Filename is the name of the file.
+	Filename string
+
+	// Pos is the position of the problem within the file.
+	Pos modfile.Position
+
+	// Message describes the problem.
+	Message string
+}
+
+// String returns the diagnostic in the form `file:line:column: message`.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", d.Filename, d.Pos.Line, d.Pos.LineRune, d.Message)
+}
+
+// incompatible is the build metadata suffix used for major versions of
+// modules that do not use semantic import versioning.
+const incompatible = "+incompatible"
+
+// canonicalVersion returns the canonical form of the given module version.
+// Unlike the canonicalization done when parsing, the casing of any
+// `+incompatible` suffix is fixed rather than the suffix being dropped, and the
+// revision of any pseudo-version is lowercased and shortened to 12
+// characters. Implements modfile.VersionFixer.
+func canonicalVersion(path, version string) (string, error) {
+	// The suffix would otherwise be dropped as build metadata.
+	if len(version) > len(incompatible) && strings.EqualFold(version[len(version)-len(incompatible):], incompatible) {
+		version = version[:len(version)-len(incompatible)] + incompatible
+	}
+
+	canonical := module.CanonicalVersion(version)
+	if canonical == "" {
+		return "", &module.ModuleError{
+			Path:    path,
+			Version: version,
+			Err:     &module.InvalidVersionError{Version: version, Err: fmt.Errorf("must be of the form v1.2.3")},
+		}
+	}
+
+	if module.IsPseudoVersion(canonical) {
+		body, build, _ := strings.Cut(canonical, "+")
+		index := strings.LastIndex(body, "-")
+
+		revision := strings.ToLower(body[index+1:])
+		if len(revision) > 12 { //nolint:mnd
+			revision = revision[:12]
+		}
+
+		canonical = body[:index+1] + revision
+		if build != "" {
+			canonical += "+" + build
+		}
+	}
+
+	return canonical, nil
+}
+
+// Check reports any non-canonical module versions, and any invalid module
+// paths, in the given data. The data is parsed as either a `go.mod` or
+// `go.work` file, in the same way as Format. Non-canonical versions can be
+// fixed by formatting with Options.Canonical, while invalid module paths must
+// be fixed by hand. If the data cannot be parsed as either kind of file, then
+// a *ParseError is returned.
+func Check(file string, data []byte) ([]Diagnostic, error) {
+	mod, work, err := parse(file, data, canonicalVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if work != nil {
+		return sortDiagnostics(checkWork(file, data, work)), nil
+	}
+
+	return sortDiagnostics(checkMod(file, data, mod)), nil
+}
+
+// sortDiagnostics sorts the given diagnostics by their position.
+func sortDiagnostics(diagnostics []Diagnostic) []Diagnostic {
+	slices.SortStableFunc(diagnostics, func(a, b Diagnostic) int {
+		return cmp.Compare(a.Pos.Byte, b.Pos.Byte)
+	})
+
+	return diagnostics
+}
+
+// checkMod reports any problems in the given `go.mod` file, which was parsed
+// from the given data.
+func checkMod(file string, data []byte, mod *modfile.File) []Diagnostic {
+	diagnostics := versionDiagnostics(file, data, mod.Syntax)
+
+	check := func(line *modfile.Line, err error) {
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Filename: file, Pos: line.Start, Message: err.Error()})
+		}
+	}
+
+	if mod.Module != nil {
+		check(mod.Module.Syntax, module.CheckImportPath(mod.Module.Mod.Path))
+	}
+
+	for _, directive := range mod.Require {
+		check(directive.Syntax, module.CheckPath(directive.Mod.Path))
+	}
+
+	for _, directive := range mod.Exclude {
+		check(directive.Syntax, module.CheckPath(directive.Mod.Path))
+	}
+
+	for _, directive := range mod.Replace {
+		check(directive.Syntax, checkReplacePaths(directive))
+	}
+
+	for _, directive := range mod.Tool {
+		check(directive.Syntax, module.CheckImportPath(directive.Path))
+	}
+
+	return diagnostics
+}
+
+// checkWork reports any problems in the given `go.work` file, which was
+// parsed from the given data.
+func checkWork(file string, data []byte, work *modfile.WorkFile) []Diagnostic {
+	diagnostics := versionDiagnostics(file, data, work.Syntax)
+
+	for _, directive := range work.Replace {
+		if err := checkReplacePaths(directive); err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Filename: file, Pos: directive.Syntax.Start, Message: err.Error()})
+		}
+	}
+
+	return diagnostics
+}
+
+// checkReplacePaths validates the module paths of the given replace
+// directive. Local replacements are not module paths, and are not validated.
+func checkReplacePaths(directive *modfile.Replace) error {
+	if err := module.CheckPath(directive.Old.Path); err != nil {
+		return err
+	}
+
+	if directive.New.Version == "" {
+		return nil
+	}
+
+	return module.CheckPath(directive.New.Path)
+}
+
+// versionDiagnostics compares the parsed tokens of every line in the given file
+// syntax against the original text of the line. Since versions are the only
+// tokens which are rewritten while parsing, each token which is not found in
+// the original text is a non-canonical version.
+func versionDiagnostics(file string, data []byte, syntax *modfile.FileSyntax) []Diagnostic {
+	var (
+		diagnostics []Diagnostic
+		lines       []*modfile.Line
+	)
+
+	for _, statement := range syntax.Stmt {
+		switch statement := statement.(type) {
+		case *modfile.Line:
+			lines = append(lines, statement)
+		case *modfile.LineBlock:
+			lines = append(lines, statement.Line...)
+		}
+	}
+
+	for _, line := range lines {
+		// The text of a line spans from the start of its first token to the
+		// end of its last token, and so contains only tokens and whitespace.
+		text := string(data[line.Start.Byte:line.End.Byte])
+
+		for index := range line.Token {
+			text = strings.TrimLeft(text, " \t\r")
+
+			original, length := originalToken(text, line.Token[index:])
+			if original != line.Token[index] {
+				pos := line.Start
+				pos.Byte = line.End.Byte - len(text)
+				pos.LineRune += utf8.RuneCount(data[line.Start.Byte:pos.Byte])
+
+				diagnostics = append(diagnostics, Diagnostic{
+					Filename: file,
+					Pos:      pos,
+					Message:  fmt.Sprintf("version %q is not canonical, should be %q", original, line.Token[index]),
+				})
+			}
+
+			text = text[length:]
+		}
+	}
+
+	return diagnostics
+}
+
+// originalToken returns the original (unquoted) text of the first of the
+// given parsed tokens, which starts at the beginning of the given line text,
+// along with its length in bytes. A token which was rewritten while parsing
+// ends where the next token starts, or at the end of the line.
+func originalToken(text string, tokens []string) (string, int) {
+	if quoted, err := strconv.QuotedPrefix(text); err == nil {
+		// Tokens which must be quoted, such as paths containing spaces, are
+		// kept quoted while parsing.
+		if quoted == tokens[0] {
+			return tokens[0], len(quoted)
+		}
+
+		unquoted, _ := strconv.Unquote(quoted)
+
+		return unquoted, len(quoted)
+	}
+
+	// Punctuation is always a token by itself, while any other token ends at
+	// whitespace or punctuation.
+	const punctuation = "()[]{},"
+
+	rest, ok := strings.CutPrefix(text, tokens[0])
+
+	switch {
+	case !ok:
+	case rest == "",
+		strings.Contains(punctuation, tokens[0]),
+		strings.ContainsRune(" \t\r"+punctuation, rune(rest[0])):
+		return tokens[0], len(tokens[0])
+	}
+
+	length := len(text)
+
+	if len(tokens) > 1 {
+		if index := strings.Index(text, tokens[1]); index > 0 {
+			length = index
+		}
+	}
+
+	return strings.TrimRight(text[:length], " \t\r"), length
+}
@@ -0,0 +1,127 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+const nonCanonical = `module example.com/foo/bar
+
+require (
+	example.com/a/a v1.2
+	example.com/b/b v2.0.0+INCOMPATIBLE
+	example.com/c/c v0.0.0-20191109021931-DAA7C04131F5568C9AEAF2EFEC7EB4C30DCA4A5C
+	nodot/d v1.0.0
+)
+
+retract [v1.0, v1.1.0]
+`
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	expected := []string{
+		`go.mod:4:18: version "v1.2" is not canonical, should be "v1.2.0"`,
+		`go.mod:5:18: version "v2.0.0+INCOMPATIBLE" is not canonical, should be "v2.0.0+incompatible"`,
+		`go.mod:6:18: version "v0.0.0-20191109021931-DAA7C04131F5568C9AEAF2EFEC7EB4C30DCA4A5C" is not canonical, should be "v0.0.0-20191109021931-daa7c04131f5"`, //nolint:lll
+		`go.mod:7:2: malformed module path "nodot/d": missing dot in first path element`,
+		`go.mod:10:10: version "v1.0" is not canonical, should be "v1.0.0"`,
+	}
+
+	diagnostics, err := modfmt.Check("go.mod", []byte(nonCanonical))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		actual = append(actual, diagnostic.String())
+	}
+
+	if !slices.Equal(expected, actual) {
+		t.Fatalf("expected:\n%q\nactual:\n%q", expected, actual)
+	}
+}
+
+func TestCheckPositions(t *testing.T) {
+	t.Parallel()
+
+	const original = `module example.com/foo/bar
+
+require "example.com/a/a" "v1.2"
+require example.com/b/b v1.2.0+meta // comment
+require example.com/ü/c v1.3
+
+replace example.com/d/d v1.0 => example.com/e/e v1.1 // comment
+replace example.com/f/f => "../f f"
+
+require example.com/g/g "v1.0.0"
+`
+
+	expected := []string{
+		`go.mod:3:27: version "v1.2" is not canonical, should be "v1.2.0"`,
+		`go.mod:4:25: version "v1.2.0+meta" is not canonical, should be "v1.2.0"`,
+		`go.mod:5:1: malformed module path "example.com/ü/c": invalid char 'ü'`,
+		`go.mod:5:25: version "v1.3" is not canonical, should be "v1.3.0"`,
+		`go.mod:7:25: version "v1.0" is not canonical, should be "v1.0.0"`,
+		`go.mod:7:49: version "v1.1" is not canonical, should be "v1.1.0"`,
+	}
+
+	diagnostics, err := modfmt.Check("go.mod", []byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		actual = append(actual, diagnostic.String())
+	}
+
+	if !slices.Equal(expected, actual) {
+		t.Fatalf("expected:\n%q\nactual:\n%q", expected, actual)
+	}
+}
+
+func TestFormatWithOptionsCanonical(t *testing.T) {
+	t.Parallel()
+
+	const expected = `module example.com/foo/bar
+
+retract (
+	[v1.0.0, v1.1.0]
+)
+
+require (
+	example.com/a/a v1.2.0
+	example.com/b/b v2.0.0+incompatible
+	example.com/c/c v0.0.0-20191109021931-daa7c04131f5
+	nodot/d v1.0.0
+)
+`
+
+	actual, err := modfmt.FormatWithOptions("go.mod", []byte(nonCanonical), modfmt.Options{Canonical: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(actual) != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, actual)
+	}
+
+	// Formatting the canonical output must not report any versions.
+	diagnostics, err := modfmt.Check("go.mod", actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected only the malformed module path, got %v", diagnostics)
+	}
+}
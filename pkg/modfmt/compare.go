@@ -0,0 +1,323 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt
+
+import (
+	"fmt"
+	"go/version"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// ChangeKind is a kind of change made to a directive.
+type ChangeKind string
+
+const (
+	// ChangeAdded is a directive which was added.
+	ChangeAdded ChangeKind = "added"
+
+	// ChangeRemoved is a directive which was removed.
+	ChangeRemoved ChangeKind = "removed"
+
+	// ChangeUpgraded is a directive whose version was increased.
+	ChangeUpgraded ChangeKind = "upgraded"
+
+	// ChangeDowngraded is a directive whose version was decreased.
+	ChangeDowngraded ChangeKind = "downgraded"
+
+	// ChangeChanged is a directive which was changed in some other way.
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Change describes a single semantic change between two files.
+type Change struct {
+	// Verb is the directive that was changed, such as `require`.
+	Verb string
+
+	// Path identifies the directive among others with the same verb, such as
+	// the module path of a `require` directive. It is empty for directives
+	// which can only appear once, such as `go`.
+	Path string
+
+	// Kind is the kind of change.
+	Kind ChangeKind
+
+	// Old is the value of the directive before the change, and is empty if
+	// the directive was added.
+	Old string
+
+	// New is the value of the directive after the change, and is empty if
+	// the directive was removed.
+	New string
+}
+
+// String returns the change in the form `verb path: kind old => new`.
+func (c Change) String() string {
+	subject := c.Verb
+	if c.Path != "" {
+		subject += " " + c.Path
+	}
+
+	switch {
+	case c.Kind == ChangeAdded && c.New == "":
+		return fmt.Sprintf("%s: %s", subject, c.Kind)
+	case c.Kind == ChangeAdded:
+		return fmt.Sprintf("%s: %s %s", subject, c.Kind, c.New)
+	case c.Kind == ChangeRemoved && c.Old == "":
+		return fmt.Sprintf("%s: %s", subject, c.Kind)
+	case c.Kind == ChangeRemoved:
+		return fmt.Sprintf("%s: %s %s", subject, c.Kind, c.Old)
+	default:
+		return fmt.Sprintf("%s: %s %s => %s", subject, c.Kind, orEmpty(c.Old), orEmpty(c.New))
+	}
+}
+
+// orEmpty returns the given value, or `""` if it is empty, such as a retraction
+// without a rationale.
+func orEmpty(value string) string {
+	if value == "" {
+		return `""`
+	}
+
+	return value
+}
+
+// Compare parses the given old and new `go.mod` or `go.work` files, and
+// returns the semantic changes between them. Formatting, comments, and the
+// order of directives are ignored, and module versions are canonicalized
+// before being compared. Changes are ordered by verb, in the order used when
+// formatting, and then by path.
+//
+// The kind of each file is guessed in the same way as Format, and both files
+// must be the same kind.
+func Compare(oldFile string, oldData []byte, newFile string, newData []byte) ([]Change, error) {
+	oldMod, oldWork, err := parse(oldFile, oldData, canonicalVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	newMod, newWork, err := parse(newFile, newData, canonicalVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case oldMod != nil && newMod != nil:
+		return compareMod(oldMod, newMod), nil
+	case oldWork != nil && newWork != nil:
+		return compareWork(oldWork, newWork), nil
+	case oldMod != nil:
+		return nil, fmt.Errorf("%s: cannot compare a %s file with a %s file", newFile, KindMod, KindWork)
+	default:
+		return nil, fmt.Errorf("%s: cannot compare a %s file with a %s file", newFile, KindWork, KindMod)
+	}
+}
+
+// compareMod returns the changes from old to new.
+func compareMod(before, after *modfile.File) []Change {
+	var changes []Change
+
+	changes = compareDirectives(changes, "module", optional(before.Module), optional(after.Module),
+		func(*modfile.Module) string { return "" },
+		func(d *modfile.Module) string { return d.Mod.Path },
+		nil)
+
+	changes = compareDirectives(changes, "go", optional(before.Go), optional(after.Go),
+		func(*modfile.Go) string { return "" },
+		func(d *modfile.Go) string { return d.Version },
+		compareGo)
+
+	changes = compareDirectives(changes, "toolchain", optional(before.Toolchain), optional(after.Toolchain),
+		func(*modfile.Toolchain) string { return "" },
+		func(d *modfile.Toolchain) string { return d.Name },
+		compareToolchain)
+
+	changes = compareDirectives(changes, "godebug", before.Godebug, after.Godebug,
+		func(d *modfile.Godebug) string { return d.Key },
+		func(d *modfile.Godebug) string { return d.Value },
+		nil)
+
+	changes = compareDirectives(changes, "retract", before.Retract, after.Retract,
+		func(d *modfile.Retract) string {
+			if d.Low == d.High {
+				return d.Low
+			}
+
+			return "[" + d.Low + ", " + d.High + "]"
+		},
+		func(d *modfile.Retract) string { return d.Rationale },
+		nil)
+
+	changes = compareDirectives(changes, "require", before.Require, after.Require,
+		func(d *modfile.Require) string { return d.Mod.Path },
+		func(d *modfile.Require) string {
+			if d.Indirect {
+				return d.Mod.Version + " // indirect"
+			}
+
+			return d.Mod.Version
+		},
+		func(before, after *modfile.Require) ChangeKind {
+			return compareVersions(semver.Compare(before.Mod.Version, after.Mod.Version))
+		})
+
+	changes = compareDirectives(changes, "exclude", before.Exclude, after.Exclude,
+		func(d *modfile.Exclude) string { return d.Mod.Path + " " + d.Mod.Version },
+		func(*modfile.Exclude) string { return "" },
+		nil)
+
+	changes = compareReplaces(changes, before.Replace, after.Replace)
+
+	changes = compareDirectives(changes, "ignore", before.Ignore, after.Ignore,
+		func(d *modfile.Ignore) string { return d.Path },
+		func(*modfile.Ignore) string { return "" },
+		nil)
+
+	changes = compareDirectives(changes, "tool", before.Tool, after.Tool,
+		func(d *modfile.Tool) string { return d.Path },
+		func(*modfile.Tool) string { return "" },
+		nil)
+
+	return changes
+}
+
+// compareWork returns the changes from old to new.
+func compareWork(before, after *modfile.WorkFile) []Change {
+	var changes []Change
+
+	changes = compareDirectives(changes, "go", optional(before.Go), optional(after.Go),
+		func(*modfile.Go) string { return "" },
+		func(d *modfile.Go) string { return d.Version },
+		compareGo)
+
+	changes = compareDirectives(changes, "toolchain", optional(before.Toolchain), optional(after.Toolchain),
+		func(*modfile.Toolchain) string { return "" },
+		func(d *modfile.Toolchain) string { return d.Name },
+		compareToolchain)
+
+	changes = compareDirectives(changes, "godebug", before.Godebug, after.Godebug,
+		func(d *modfile.Godebug) string { return d.Key },
+		func(d *modfile.Godebug) string { return d.Value },
+		nil)
+
+	changes = compareDirectives(changes, "use", before.Use, after.Use,
+		func(d *modfile.Use) string { return d.Path },
+		func(*modfile.Use) string { return "" },
+		nil)
+
+	return compareReplaces(changes, before.Replace, after.Replace)
+}
+
+// compareDirectives appends the changes from the old to the new directives to
+// the given changes. Directives are matched by their key, and compared by
+// their value. The kind of a changed directive is decided by the given
+// function, or is ChangeChanged if it is nil. Changes are appended in order
+// of their key.
+func compareDirectives[T any](
+	changes []Change,
+	verb string,
+	before, after []T,
+	key func(T) string,
+	value func(T) string,
+	kind func(before, after T) ChangeKind,
+) []Change {
+	olds := make(map[string]T, len(before))
+	for _, directive := range before {
+		olds[key(directive)] = directive
+	}
+
+	news := make(map[string]T, len(after))
+	for _, directive := range after {
+		news[key(directive)] = directive
+	}
+
+	keys := make([]string, 0, len(olds)+len(news))
+	for k := range olds {
+		keys = append(keys, k)
+	}
+
+	for k := range news {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	for _, k := range keys {
+		o, inOld := olds[k]
+		n, inNew := news[k]
+
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Verb: verb, Path: k, Kind: ChangeAdded, New: value(n)})
+		case !inNew:
+			changes = append(changes, Change{Verb: verb, Path: k, Kind: ChangeRemoved, Old: value(o)})
+		case value(o) != value(n):
+			change := Change{Verb: verb, Path: k, Kind: ChangeChanged, Old: value(o), New: value(n)}
+			if kind != nil {
+				change.Kind = kind(o, n)
+			}
+
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// compareReplaces appends the changes from the old to the new replace
+// directives to the given changes. A replacement with a different version of
+// the same module is an upgrade or downgrade.
+func compareReplaces(changes []Change, before, after []*modfile.Replace) []Change {
+	return compareDirectives(changes, "replace", before, after,
+		func(d *modfile.Replace) string {
+			if d.Old.Version == "" {
+				return d.Old.Path
+			}
+
+			return d.Old.Path + " " + d.Old.Version
+		},
+		replacement,
+		func(before, after *modfile.Replace) ChangeKind {
+			if before.New.Path != after.New.Path || before.New.Version == "" || after.New.Version == "" {
+				return ChangeChanged
+			}
+
+			return compareVersions(semver.Compare(before.New.Version, after.New.Version))
+		})
+}
+
+// compareGo returns whether the version of a `go` directive was upgraded or
+// downgraded.
+func compareGo(before, after *modfile.Go) ChangeKind {
+	return compareVersions(version.Compare("go"+before.Version, "go"+after.Version))
+}
+
+// compareToolchain returns whether the version of a `toolchain` directive was
+// upgraded or downgraded. Toolchains which are not versions, such as
+// `default`, are only ever changed.
+func compareToolchain(before, after *modfile.Toolchain) ChangeKind {
+	if !version.IsValid(before.Name) || !version.IsValid(after.Name) {
+		return ChangeChanged
+	}
+
+	return compareVersions(version.Compare(before.Name, after.Name))
+}
+
+// compareVersions returns the kind of change for the given comparison of an
+// old and new version.
+func compareVersions(comparison int) ChangeKind {
+	switch {
+	case comparison < 0:
+		return ChangeUpgraded
+	case comparison > 0:
+		return ChangeDowngraded
+	default:
+		return ChangeChanged
+	}
+}
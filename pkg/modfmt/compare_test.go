@@ -0,0 +1,150 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		oldFile  string
+		old      string
+		newFile  string
+		new      string
+		expected []string
+		err      string
+	}{
+		{
+			name:    "mod",
+			oldFile: "old/go.mod",
+			old: `module example.com/foo/bar
+
+go 1.23.0
+
+toolchain go1.23.4
+
+retract v1.0.0 // Published accidentally.
+
+require (
+	example.com/a/a v1.0.0
+	example.com/b/b v1.2.0
+	example.com/c/c v1.0.0
+	example.com/d/d v1.0.0 // indirect
+	example.com/e/e v1.0.0
+)
+
+replace example.com/f/f => example.com/g/g v1.0.0
+
+replace example.com/h/h => ../h
+`,
+			newFile: "new/go.mod",
+			new: `// A comment.
+module example.com/foo/bar
+go 1.24.0
+require example.com/e/e v1.0
+require example.com/a/a v1.1.0
+require example.com/b/b v1.1.0
+require example.com/d/d v1.0.0
+require example.com/x/x v1.0.0 // indirect
+retract v1.0.0 // Published accidentally.
+retract [v1.1.0, v1.2.0]
+replace example.com/f/f => example.com/g/g v1.1.0
+replace example.com/h/h => ../i
+`,
+			expected: []string{
+				"go: upgraded 1.23.0 => 1.24.0",
+				"toolchain: removed go1.23.4",
+				"retract [v1.1.0, v1.2.0]: added",
+				"require example.com/a/a: upgraded v1.0.0 => v1.1.0",
+				"require example.com/b/b: downgraded v1.2.0 => v1.1.0",
+				"require example.com/c/c: removed v1.0.0",
+				"require example.com/d/d: changed v1.0.0 // indirect => v1.0.0",
+				"require example.com/x/x: added v1.0.0 // indirect",
+				"replace example.com/f/f: upgraded example.com/g/g v1.0.0 => example.com/g/g v1.1.0",
+				"replace example.com/h/h: changed ../h => ../i",
+			},
+		},
+		{
+			name:    "work",
+			oldFile: "go.work",
+			old: `go 1.23.0
+
+use (
+	./a
+	./b
+)
+`,
+			newFile: "go.work",
+			new: `go 1.23.0
+
+use ./b
+use ./c
+`,
+			expected: []string{
+				"use ./a: removed",
+				"use ./c: added",
+			},
+		},
+		{
+			name:    "unchanged",
+			oldFile: "go.mod",
+			old: `module example.com/foo/bar
+
+require (
+	example.com/a/a v1.0.0
+	example.com/b/b v1.0.0
+)
+`,
+			newFile: "go.mod",
+			new: `module example.com/foo/bar
+require example.com/b/b v1.0.0 // A comment.
+require example.com/a/a v1.0.0
+`,
+		},
+		{
+			name:    "mismatched",
+			oldFile: "go.mod",
+			old:     "module example.com/foo/bar\n",
+			newFile: "go.work",
+			new:     "use ./a\n",
+			err:     "go.work: cannot compare a go.mod file with a go.work file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			changes, err := modfmt.Compare(test.oldFile, []byte(test.old), test.newFile, []byte(test.new))
+
+			switch {
+			case test.err != "" && err == nil:
+				t.Fatal("expected an error")
+			case test.err != "" && err.Error() != test.err:
+				t.Fatalf("expected error %q, actual %q", test.err, err.Error())
+			case test.err == "" && err != nil:
+				t.Fatal(err)
+			}
+
+			actual := make([]string, 0, len(changes))
+			for _, change := range changes {
+				actual = append(actual, change.String())
+			}
+
+			expected := strings.Join(test.expected, "\n")
+			if strings.Join(actual, "\n") != expected {
+				t.Fatalf("expected:\n%s\nactual:\n%s", expected, strings.Join(actual, "\n"))
+			}
+		})
+	}
+}
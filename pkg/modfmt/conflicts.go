@@ -0,0 +1,267 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// Git conflict markers, which start a line.
+//
+// See https://git-scm.com/docs/git-merge#_how_conflicts_are_presented
+const (
+	markerOurs   = "<<<<<<<"
+	markerBase   = "|||||||"
+	markerSplit  = "======="
+	markerTheirs = ">>>>>>>"
+)
+
+// conflictLine is a single line of a file with its conflicts merged.
+type conflictLine struct {
+	// text is the text of the line, including any trailing newline.
+	text string
+
+	// line is the line number of the line in the original file.
+	line int
+
+	// start is the byte offset of the line in the original file.
+	start int
+
+	// conflicted is true if the line came from either side of a conflict.
+	conflicted bool
+}
+
+// mergeConflictLines merges both sides of every git conflict in the given
+// data, by keeping the lines from our side of each conflict followed by the
+// lines from their side. Lines from their side which are also on our side are
+// dropped, as are any lines from the merge base. Returns nil if the data has
+// no conflicts.
+func mergeConflictLines(file string, data []byte) ([]conflictLine, error) {
+	const (
+		stateNone = iota
+		stateOurs
+		stateBase
+		stateTheirs
+	)
+
+	var (
+		results []conflictLine
+		ours    []string
+		state   = stateNone
+		opened  modfile.Position
+		found   bool
+		offset  int
+	)
+
+	for index, text := range strings.SplitAfter(string(data), "\n") {
+		line := conflictLine{text: text, line: index + 1, start: offset}
+		pos := modfile.Position{Line: line.line, LineRune: 1, Byte: offset}
+		offset += len(text)
+
+		marker := func(prefix string) bool {
+			return strings.HasPrefix(text, prefix)
+		}
+
+		unexpected := func() error {
+			return modfile.ErrorList{{Filename: file, Pos: pos, Err: errors.New("unexpected conflict marker")}}
+		}
+
+		switch {
+		case marker(markerOurs):
+			if state != stateNone {
+				return nil, unexpected()
+			}
+
+			state, opened, found, ours = stateOurs, pos, true, nil
+		case marker(markerBase):
+			if state != stateOurs {
+				return nil, unexpected()
+			}
+
+			state = stateBase
+		case marker(markerSplit) && state != stateNone:
+			if state == stateTheirs {
+				return nil, unexpected()
+			}
+
+			state = stateTheirs
+		case marker(markerTheirs):
+			if state != stateTheirs {
+				return nil, unexpected()
+			}
+
+			state = stateNone
+		case state == stateBase:
+			// The merge base is superseded by both sides.
+		case state == stateTheirs && slices.Contains(ours, strings.TrimSpace(text)):
+			// Lines on both sides of the conflict are only kept once.
+		default:
+			if state == stateOurs {
+				ours = append(ours, strings.TrimSpace(text))
+			}
+
+			line.conflicted = state != stateNone
+			results = append(results, line)
+		}
+	}
+
+	if state != stateNone {
+		return nil, modfile.ErrorList{{Filename: file, Pos: opened, Err: errors.New("unterminated conflict")}}
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return results, nil
+}
+
+// resolveConflicts parses the given data as a `go.mod` file containing git
+// conflicts. Both sides of every conflict are merged, and must only contain
+// `require`, `exclude`, and `replace` directives. Duplicate requirements and
+// exclusions are later merged while formatting, and duplicate replacements
+// are merged here. Returns a nil file if the data has no conflicts. The
+// positions of any errors refer to the original data.
+func resolveConflicts(file string, data []byte, fix modfile.VersionFixer) (*modfile.File, error) {
+	lines, err := mergeConflictLines(file, data)
+	if err != nil || lines == nil {
+		return nil, err
+	}
+
+	if isWork(file) {
+		return nil, fmt.Errorf("%s: conflicts can only be resolved in go.mod files", file)
+	}
+
+	var merged strings.Builder
+	for _, line := range lines {
+		merged.WriteString(line.text)
+	}
+
+	// Positions within the merged data are mapped back to the original data.
+	original := func(pos modfile.Position) modfile.Position {
+		if pos.Line < 1 || pos.Line > len(lines) {
+			return pos
+		}
+
+		line := lines[pos.Line-1]
+		start := 0
+
+		for _, previous := range lines[:pos.Line-1] {
+			start += len(previous.text)
+		}
+
+		return modfile.Position{Line: line.line, LineRune: pos.LineRune, Byte: line.start + pos.Byte - start}
+	}
+
+	mod, err := modfile.Parse(file, []byte(merged.String()), fix)
+	if err != nil {
+		var list modfile.ErrorList
+		if !errors.As(err, &list) {
+			return nil, err
+		}
+
+		for index := range list {
+			list[index].Pos = original(list[index].Pos)
+		}
+
+		return nil, list
+	}
+
+	var errs modfile.ErrorList
+
+	mergeable := make(map[*modfile.Line]bool)
+	for _, directive := range mod.Require {
+		mergeable[directive.Syntax] = true
+	}
+
+	for _, directive := range mod.Exclude {
+		mergeable[directive.Syntax] = true
+	}
+
+	for _, directive := range mod.Replace {
+		mergeable[directive.Syntax] = true
+	}
+
+	check := func(line *modfile.Line, verb string) {
+		if lines[line.Start.Line-1].conflicted && !mergeable[line] {
+			errs = append(errs, modfile.Error{
+				Filename: file,
+				Pos:      original(line.Start),
+				Verb:     verb,
+				Err:      errors.New("cannot merge conflicting directive"),
+			})
+		}
+	}
+
+	for _, statement := range mod.Syntax.Stmt {
+		switch statement := statement.(type) {
+		case *modfile.Line:
+			check(statement, statement.Token[0])
+		case *modfile.LineBlock:
+			for _, line := range statement.Line {
+				check(line, statement.Token[0])
+			}
+		}
+	}
+
+	// Replacements are unique by module path and version. Replacements with
+	// different versions of the same module are merged by keeping the
+	// highest version.
+	mod.Replace = dedupeFunc(mod.Replace,
+		func(r *modfile.Replace) string {
+			return r.Old.Path + " " + r.Old.Version
+		},
+		func(kept, duplicate *modfile.Replace) *modfile.Replace {
+			winner, loser := kept, duplicate
+
+			switch {
+			case kept.New == duplicate.New:
+			case kept.New.Path == duplicate.New.Path && kept.New.Version != "" && duplicate.New.Version != "":
+				if semver.Compare(duplicate.New.Version, kept.New.Version) > 0 {
+					winner, loser = duplicate, kept
+				}
+			default:
+				errs = append(errs, modfile.Error{
+					Filename: file,
+					Pos:      original(duplicate.Syntax.Start),
+					Verb:     "replace",
+					ModPath:  duplicate.Old.Path,
+					Err:      fmt.Errorf("conflicting replacements %s and %s", replacement(kept), replacement(duplicate)),
+				})
+			}
+
+			mergeComments(winner.Syntax, loser.Syntax)
+
+			return winner
+		})
+
+	if len(errs) > 0 {
+		slices.SortStableFunc(errs, func(a, b modfile.Error) int {
+			return cmp.Compare(a.Pos.Byte, b.Pos.Byte)
+		})
+
+		return nil, errs
+	}
+
+	return mod, nil
+}
+
+// replacement returns the target of the given replace directive, as it would
+// be written.
+func replacement(directive *modfile.Replace) string {
+	if directive.New.Version == "" {
+		return directive.New.Path
+	}
+
+	return directive.New.Path + " " + directive.New.Version
+}
@@ -0,0 +1,151 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// dedupe removes any duplicate `require`, `exclude`, and `tool` directives
+// from the given modfile.File, merging the comments of each duplicate into the
+// directive that is kept. Returns an error if conflicting versions of the same
+// module are required, and conflicts are being reported.
+func dedupe(mod *modfile.File, opts Options) error {
+	var errs modfile.ErrorList
+
+	// Requirements are unique by module path. A direct requirement always
+	// wins over an indirect one.
+	mod.Require = dedupeFunc(mod.Require,
+		func(r *modfile.Require) string {
+			return r.Mod.Path
+		},
+		func(kept, duplicate *modfile.Require) *modfile.Require {
+			winner, loser := kept, duplicate
+
+			if kept.Mod.Version != duplicate.Mod.Version {
+				if opts.Conflicts == ConflictError {
+					errs = append(errs, modfile.Error{
+						Filename: mod.Syntax.Name,
+						Pos:      duplicate.Syntax.Start,
+						Verb:     "require",
+						ModPath:  duplicate.Mod.Path,
+						Err:      fmt.Errorf("conflicting versions %s and %s", kept.Mod.Version, duplicate.Mod.Version),
+					})
+				}
+
+				if semver.Compare(duplicate.Mod.Version, kept.Mod.Version) > 0 {
+					winner, loser = duplicate, kept
+				}
+			}
+
+			if loser.Indirect {
+				dropIndirect(loser.Syntax)
+			}
+
+			if winner.Indirect && !loser.Indirect {
+				dropIndirect(winner.Syntax)
+				winner.Indirect = false
+			}
+
+			mergeComments(winner.Syntax, loser.Syntax)
+
+			return winner
+		})
+
+	// Exclusions are unique by module path and version.
+	mod.Exclude = dedupeFunc(mod.Exclude,
+		func(e *modfile.Exclude) string {
+			return e.Mod.Path + " " + e.Mod.Version
+		},
+		func(kept, duplicate *modfile.Exclude) *modfile.Exclude {
+			mergeComments(kept.Syntax, duplicate.Syntax)
+
+			return kept
+		})
+
+	// Tools are unique by package path.
+	mod.Tool = dedupeFunc(mod.Tool,
+		func(t *modfile.Tool) string {
+			return t.Path
+		},
+		func(kept, duplicate *modfile.Tool) *modfile.Tool {
+			mergeComments(kept.Syntax, duplicate.Syntax)
+
+			return kept
+		})
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// dedupeFunc removes any directives from the given slice which have the same
+// key as an earlier directive. Each pair of duplicates is passed to the given
+// merge function, which returns the directive to keep in place of the earlier
+// directive.
+func dedupeFunc[T any](directives []T, key func(T) string, merge func(kept, duplicate T) T) []T {
+	results := make([]T, 0, len(directives))
+	indices := make(map[string]int, len(directives))
+
+	for _, directive := range directives {
+		index, ok := indices[key(directive)]
+		if !ok {
+			indices[key(directive)] = len(results)
+			results = append(results, directive)
+
+			continue
+		}
+
+		results[index] = merge(results[index], directive)
+	}
+
+	return results
+}
+
+// dropIndirect removes the `// indirect` marker from the suffix comments of
+// the given line, keeping any text that followed the marker as a comment.
+func dropIndirect(line *modfile.Line) {
+	rest := indirectRemainder(line.Suffix[0].Token)
+	if rest == "" {
+		line.Suffix = slices.Clone(line.Suffix[1:])
+
+		return
+	}
+
+	line.Suffix = slices.Concat([]modfile.Comment{{Token: "// " + rest}}, line.Suffix[1:])
+}
+
+// mergeComments adds the comments from the source line to the target line,
+// skipping any comments that the target line already has.
+func mergeComments(target, source *modfile.Line) {
+	for _, comment := range source.Before {
+		if !isBlank(comment) && !hasComment(target, comment) {
+			target.Before = append(target.Before, comment)
+		}
+	}
+
+	for _, comment := range source.Suffix {
+		if !hasComment(target, comment) {
+			target.Suffix = append(target.Suffix, comment)
+		}
+	}
+}
+
+// hasComment reports if the given line already has the given comment, either
+// before or after the line.
+func hasComment(line *modfile.Line, comment modfile.Comment) bool {
+	matches := func(other modfile.Comment) bool {
+		return other.Token == comment.Token
+	}
+
+	return slices.ContainsFunc(line.Before, matches) || slices.ContainsFunc(line.Suffix, matches)
+}
@@ -9,6 +9,7 @@ package modfmt
 import (
 	"bytes"
 	"io"
+	"path/filepath"
 	"slices"
 	"strings"
 
@@ -17,59 +18,136 @@ import (
 )
 
 // Format attempts to parse and format the given data as either a `go.mod` or
-// `go.work` file.
+// `go.work` file. If the given file name ends with `.work` then the data is
+// first parsed as a `go.work` file, otherwise it is first parsed as a `go.mod`
+// file.
 func Format(file string, data []byte) ([]byte, error) {
-	// First, attempt to parse and format the given data as a go.mod file.
-	formatted, errmod := FormatMod(file, data)
-	if errmod == nil {
-		return formatted, nil
+	return FormatWithOptions(file, data, Options{})
+}
+
+// FormatWithOptions is like Format, but formats the given data according to
+// the given options. If the data cannot be parsed as either kind of file, then
+// a *ParseError is returned.
+func FormatWithOptions(file string, data []byte, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if opts.ResolveConflicts {
+		mod, err := resolveConflicts(file, data, opts.versionFixer())
+		if err != nil {
+			return nil, err
+		}
+
+		if mod != nil {
+			// Both sides of each conflict are merged as duplicates.
+			opts.Dedupe, opts.Conflicts = true, ConflictHighest
+
+			if err := formatMod(mod, &buf, opts); err != nil {
+				return nil, err
+			}
+
+			return buf.Bytes(), nil
+		}
 	}
 
-	// Second, attempt to parse and format the given data as a go.work file.
-	formatted, errwork := FormatWork(file, data)
-	if errwork == nil {
-		return formatted, nil
+	mod, work, err := parse(file, data, opts.versionFixer())
+	if err != nil {
+		return nil, err
 	}
 
-	// If both attempts failed, then return the error from the initial go.mod
-	// attempt.
-	return nil, errmod
+	if work != nil {
+		formatWork(work, &buf, opts)
+
+		return buf.Bytes(), nil
+	}
+
+	if err := formatMod(mod, &buf, opts); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// isWork reports if the given file name looks like a `go.work` file.
+func isWork(file string) bool {
+	return strings.HasSuffix(filepath.Base(file), ".work")
 }
 
 // FormatMod attempts to parse and format the given data as a `go.mod` file.
 func FormatMod(file string, data []byte) ([]byte, error) {
-	mod, err := modfile.Parse(file, data, nil)
+	return FormatModWithOptions(file, data, Options{})
+}
+
+// FormatModWithOptions is like FormatMod, but formats the given data according
+// to the given options.
+func FormatModWithOptions(file string, data []byte, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	mod, err := modfile.Parse(file, data, opts.versionFixer())
 	if err != nil {
 		return nil, err
 	}
 
 	var buf bytes.Buffer
-	formatMod(mod, &buf)
+	if err := formatMod(mod, &buf, opts); err != nil {
+		return nil, err
+	}
 
 	return buf.Bytes(), nil
 }
 
-// formatMod updates, sorts, & formats the given modfile.File.
+// formatMod updates, deduplicates, sorts, & formats the given modfile.File.
 //
 // See https://go.dev/ref/mod#go-mod-file
-func formatMod(mod *modfile.File, w io.Writer) {
-	// sort `exclude (…)` directives by module path.
+func formatMod(mod *modfile.File, w io.Writer, opts Options) error {
+	// remove duplicate `require`, `exclude`, and `tool` directives.
+	if opts.Dedupe {
+		if err := dedupe(mod, opts); err != nil {
+			return err
+		}
+	}
+
+	f := newFormatter(mod.Syntax, opts)
+
+	// sort `exclude (…)` directives by module path. If groups are being kept,
+	// then all directives are first sorted by group.
 	slices.SortFunc(mod.Exclude, func(a, b *modfile.Exclude) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		return strings.Compare(a.Mod.Path, b.Mod.Path)
 	})
 
 	// sort `godebug (…)` directives by key.
 	slices.SortFunc(mod.Godebug, func(a, b *modfile.Godebug) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		return strings.Compare(a.Key, b.Key)
 	})
 
 	// sort `ignore (…)` directives by file path.
 	slices.SortFunc(mod.Ignore, func(a, b *modfile.Ignore) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		return strings.Compare(a.Path, b.Path)
 	})
 
 	// sort `replace (…)` directives by module path, then by version.
 	slices.SortFunc(mod.Replace, func(a, b *modfile.Replace) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		if cmp := strings.Compare(a.Old.Path, b.Old.Path); cmp != 0 {
 			return cmp
 		}
@@ -79,11 +157,19 @@ func formatMod(mod *modfile.File, w io.Writer) {
 
 	// sort `require (…)` directives by module path.
 	slices.SortFunc(mod.Require, func(a, b *modfile.Require) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		return strings.Compare(a.Mod.Path, b.Mod.Path)
 	})
 
 	// sort `retract (…)` directives by version.
 	slices.SortFunc(mod.Retract, func(a, b *modfile.Retract) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		if cmp := semver.Compare(a.Low, b.Low); cmp != 0 {
 			return cmp
 		}
@@ -93,35 +179,51 @@ func formatMod(mod *modfile.File, w io.Writer) {
 
 	// sort `tool (…)` directives by module path.
 	slices.SortFunc(mod.Tool, func(a, b *modfile.Tool) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		return strings.Compare(a.Path, b.Path)
 	})
 
-	joinSections(w,
-		sectionHeader(mod.Syntax),
-		sectionModule(mod.Module),
-		sectionGo(mod.Go),
-		sectionToolchain(mod.Toolchain),
-		sectionGodebug(mod.Godebug),
-		sectionRetract(mod.Retract),
-		sectionRequire(mod.Require),
-		sectionRequireIndirect(mod.Require),
-		sectionIgnore(mod.Ignore),
-		sectionExclude(mod.Exclude),
-		sectionReplace(mod.Replace),
-		sectionReplaceLocal(mod.Replace),
-		sectionTool(mod.Tool),
-	)
+	joinSections(w, opts.order(defaultModOrder, map[Section]func() string{
+		SectionHeader:          func() string { return f.sectionHeader(mod.Syntax) },
+		SectionModule:          func() string { return f.sectionModule(mod.Module) },
+		SectionGo:              func() string { return f.sectionGo(mod.Go) },
+		SectionToolchain:       func() string { return f.sectionToolchain(mod.Toolchain) },
+		SectionGodebug:         func() string { return f.sectionGodebug(mod.Godebug) },
+		SectionRetract:         func() string { return f.sectionRetract(mod.Retract) },
+		SectionRequire:         func() string { return f.sectionRequire(mod.Require) },
+		SectionRequireIndirect: func() string { return f.sectionRequireIndirect(mod.Require) },
+		SectionIgnore:          func() string { return f.sectionIgnore(mod.Ignore) },
+		SectionExclude:         func() string { return f.sectionExclude(mod.Exclude) },
+		SectionReplace:         func() string { return f.sectionReplace(mod.Replace) },
+		SectionReplaceLocal:    func() string { return f.sectionReplaceLocal(mod.Replace) },
+		SectionTool:            func() string { return f.sectionTool(mod.Tool) },
+	})...)
+
+	return nil
 }
 
 // FormatWork attempts to parse and format the given data as a `go.work` file.
 func FormatWork(file string, data []byte) ([]byte, error) {
-	work, err := modfile.ParseWork(file, data, nil)
+	return FormatWorkWithOptions(file, data, Options{})
+}
+
+// FormatWorkWithOptions is like FormatWork, but formats the given data
+// according to the given options.
+func FormatWorkWithOptions(file string, data []byte, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	work, err := modfile.ParseWork(file, data, opts.versionFixer())
 	if err != nil {
 		return nil, err
 	}
 
 	var buf bytes.Buffer
-	formatWork(work, &buf)
+	formatWork(work, &buf, opts)
 
 	return buf.Bytes(), nil
 }
@@ -129,14 +231,24 @@ func FormatWork(file string, data []byte) ([]byte, error) {
 // formatWork updates, sorts, & formats the given modfile.WorkFile.
 //
 // See https://go.dev/ref/mod#go-work-file
-func formatWork(work *modfile.WorkFile, w io.Writer) {
+func formatWork(work *modfile.WorkFile, w io.Writer, opts Options) {
+	f := newFormatter(work.Syntax, opts)
+
 	// sort `godebug (…)` directives by key.
 	slices.SortFunc(work.Godebug, func(a, b *modfile.Godebug) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		return strings.Compare(a.Key, b.Key)
 	})
 
 	// sort `replace (…)` directives by module path, then by version.
 	slices.SortFunc(work.Replace, func(a, b *modfile.Replace) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		if cmp := strings.Compare(a.Old.Path, b.Old.Path); cmp != 0 {
 			return cmp
 		}
@@ -146,18 +258,22 @@ func formatWork(work *modfile.WorkFile, w io.Writer) {
 
 	// sort `use (…)` directives by file path.
 	slices.SortFunc(work.Use, func(a, b *modfile.Use) int {
+		if cmp := f.compareGroups(a.Syntax, b.Syntax); cmp != 0 {
+			return cmp
+		}
+
 		return strings.Compare(a.Path, b.Path)
 	})
 
-	joinSections(w,
-		sectionHeader(work.Syntax),
-		sectionGo(work.Go),
-		sectionToolchain(work.Toolchain),
-		sectionGodebug(work.Godebug),
-		sectionUse(work.Use),
-		sectionReplace(work.Replace),
-		sectionReplaceLocal(work.Replace),
-	)
+	joinSections(w, opts.order(defaultWorkOrder, map[Section]func() string{
+		SectionHeader:       func() string { return f.sectionHeader(work.Syntax) },
+		SectionGo:           func() string { return f.sectionGo(work.Go) },
+		SectionToolchain:    func() string { return f.sectionToolchain(work.Toolchain) },
+		SectionGodebug:      func() string { return f.sectionGodebug(work.Godebug) },
+		SectionUse:          func() string { return f.sectionUse(work.Use) },
+		SectionReplace:      func() string { return f.sectionReplace(work.Replace) },
+		SectionReplaceLocal: func() string { return f.sectionReplaceLocal(work.Replace) },
+	})...)
 }
 
 // joinSections writes each non-empty section to the given io.Writer with a
@@ -7,11 +7,15 @@ package modfmt_test
 
 import (
 	"bytes"
+	"errors"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"golang.org/x/mod/modfile"
+
 	"github.com/joshdk/modfmt/pkg/modfmt"
 )
 
@@ -58,3 +62,475 @@ func TestFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatWithOptions(t *testing.T) {
+	t.Parallel()
+
+	const original = `module example.com/foo/bar
+
+//   go comment
+go 1.23.0
+
+require example.com/b/b v1.2.2 // indirect
+require example.com/a/a v1.1.1
+
+replace example.com/c/c => ../c
+replace example.com/d/d => example.com/e/e v1.0.0
+`
+
+	tests := []struct {
+		name     string
+		opts     modfmt.Options
+		expected string
+	}{
+		{
+			name: "defaults",
+			expected: `module example.com/foo/bar
+
+// go comment
+go 1.23.0
+
+require (
+	example.com/a/a v1.1.1
+)
+
+require (
+	example.com/b/b v1.2.2 // indirect
+)
+
+replace (
+	example.com/d/d => example.com/e/e v1.0.0
+)
+
+replace (
+	example.com/c/c => ../c
+)
+`,
+		},
+		{
+			name: "order",
+			opts: modfmt.Options{
+				Order: []modfmt.Section{modfmt.SectionReplaceLocal, modfmt.SectionGo},
+			},
+			expected: `module example.com/foo/bar
+
+replace (
+	example.com/c/c => ../c
+)
+
+// go comment
+go 1.23.0
+
+require (
+	example.com/a/a v1.1.1
+)
+
+require (
+	example.com/b/b v1.2.2 // indirect
+)
+
+replace (
+	example.com/d/d => example.com/e/e v1.0.0
+)
+`,
+		},
+		{
+			name: "merge",
+			opts: modfmt.Options{
+				MergeIndirect: true,
+				MergeLocal:    true,
+			},
+			expected: `module example.com/foo/bar
+
+// go comment
+go 1.23.0
+
+require (
+	example.com/a/a v1.1.1
+	example.com/b/b v1.2.2 // indirect
+)
+
+replace (
+	example.com/c/c => ../c
+	example.com/d/d => example.com/e/e v1.0.0
+)
+`,
+		},
+		{
+			name: "collapse",
+			opts: modfmt.Options{
+				Collapse: true,
+				Comments: modfmt.CommentsPreserve,
+			},
+			expected: `module example.com/foo/bar
+
+//   go comment
+go 1.23.0
+
+require example.com/a/a v1.1.1
+
+require example.com/b/b v1.2.2 // indirect
+
+replace example.com/d/d => example.com/e/e v1.0.0
+
+replace example.com/c/c => ../c
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := modfmt.FormatWithOptions("go.mod", []byte(original), test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(actual) != test.expected {
+				t.Fatalf("expected:\n%s\nactual:\n%s", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFormatWithOptionsDeprecated(t *testing.T) {
+	t.Parallel()
+
+	const original = `//   Deprecated:   use example.com/foo/baz
+//
+//   other comment
+module example.com/foo/bar
+`
+
+	const expected = `//   other comment
+//
+// Deprecated: use example.com/foo/baz
+module example.com/foo/bar
+`
+
+	actual, err := modfmt.FormatWithOptions("go.mod", []byte(original), modfmt.Options{Comments: modfmt.CommentsPreserve})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(actual) != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestFormatWithOptionsRetractRationale(t *testing.T) {
+	t.Parallel()
+
+	originalFile := filepath.Join(testdataDir, "retract.mod")
+
+	originalData, err := os.ReadFile(originalFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rationales := func(t *testing.T, data []byte) map[string]string {
+		t.Helper()
+
+		mod, err := modfile.Parse(originalFile, data, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results := make(map[string]string)
+		for _, directive := range mod.Retract {
+			results[directive.Low+" "+directive.High] = directive.Rationale
+		}
+
+		return results
+	}
+
+	expected := rationales(t, originalData)
+
+	tests := []struct {
+		name string
+		opts modfmt.Options
+	}{
+		{name: "defaults"},
+		{name: "keep groups", opts: modfmt.Options{KeepGroups: true}},
+		{name: "preserve", opts: modfmt.Options{Comments: modfmt.CommentsPreserve, Collapse: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			formatted, err := modfmt.FormatWithOptions(originalFile, originalData, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actual := rationales(t, formatted); !maps.Equal(expected, actual) {
+				t.Fatalf("expected rationales:\n%q\nactual rationales:\n%q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestFormatWithOptionsKeepGroups(t *testing.T) {
+	t.Parallel()
+
+	const original = `module example.com/foo/bar
+
+go 1.23.0
+
+require (
+	// internal libs
+	example.com/z/z v1.0.0
+	example.com/a/a v1.0.0 // a comment
+
+	// third-party
+	github.com/y/y v1.0.0
+	github.com/b/b v1.0.0
+)
+`
+
+	const expected = `module example.com/foo/bar
+
+go 1.23.0
+
+require (
+	// internal libs
+	// a comment
+	example.com/a/a v1.0.0
+	example.com/z/z v1.0.0
+
+	// third-party
+	github.com/b/b v1.0.0
+	github.com/y/y v1.0.0
+)
+`
+
+	actual, err := modfmt.FormatWithOptions("go.mod", []byte(original), modfmt.Options{KeepGroups: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(actual) != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestFormatWithOptionsFixtures(t *testing.T) {
+	t.Parallel()
+
+	// Each subdirectory of testdata holds files formatted with these options.
+	fixtures := map[string]modfmt.Options{
+		"dedupe":               {Dedupe: true},
+		"keep-groups":          {KeepGroups: true},
+		"keep-groups-collapse": {KeepGroups: true, Collapse: true},
+	}
+
+	for name, opts := range fixtures {
+		dir := filepath.Join(testdataDir, name)
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), ".mod") && !strings.HasSuffix(entry.Name(), ".work") {
+				continue
+			}
+
+			t.Run(name+"/"+entry.Name(), func(t *testing.T) {
+				t.Parallel()
+
+				originalFile := filepath.Join(dir, entry.Name())
+
+				originalData, err := os.ReadFile(originalFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				formattedFile := filepath.Join(dir, entry.Name()+".formatted")
+
+				expectedData, err := os.ReadFile(formattedFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				actualData, err := modfmt.FormatWithOptions(originalFile, originalData, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !bytes.Equal(expectedData, actualData) {
+					t.Fatalf("formatted %s differed from %s:\n%s", originalFile, formattedFile, actualData)
+				}
+
+				// Formatting must be stable, so formatting again changes nothing.
+				againData, err := modfmt.FormatWithOptions(formattedFile, expectedData, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !bytes.Equal(expectedData, againData) {
+					t.Fatalf("formatting %s again changed it:\n%s", formattedFile, againData)
+				}
+			})
+		}
+	}
+}
+
+func TestFormatWithOptionsConflictError(t *testing.T) {
+	t.Parallel()
+
+	const original = `module example.com/foo/bar
+
+go 1.23.0
+
+require example.com/a/a v1.1.1
+require example.com/a/a v1.1.1
+require example.com/b/b v1.2.0
+require example.com/b/b v1.3.0
+`
+
+	const expected = "go.mod:8: require example.com/b/b: conflicting versions v1.2.0 and v1.3.0"
+
+	_, err := modfmt.FormatWithOptions("go.mod", []byte(original), modfmt.Options{Dedupe: true, Conflicts: modfmt.ConflictError})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if err.Error() != expected {
+		t.Fatalf("expected error %q, actual %q", expected, err.Error())
+	}
+}
+
+func TestFormatParseError(t *testing.T) {
+	t.Parallel()
+
+	const original = `go 1.23.0
+
+use ./foo
+require example.com/a/a
+`
+
+	_, err := modfmt.Format("go.work", []byte(original))
+
+	var parseErr *modfmt.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a parse error, actual %v", err)
+	}
+
+	if parseErr.Kind != modfmt.KindWork {
+		t.Fatalf("expected kind %q, actual %q", modfmt.KindWork, parseErr.Kind)
+	}
+
+	const expected = "go.work:4: unknown directive: require"
+	if parseErr.Error() != expected {
+		t.Fatalf("expected error %q, actual %q", expected, parseErr.Error())
+	}
+
+	if parseErr.OtherErr == nil {
+		t.Fatal("expected an error from parsing as a go.mod file")
+	}
+
+	positions := parseErr.Positions()
+	if len(positions) != 1 || positions[0].Line != 4 || positions[0].LineRune != 1 {
+		t.Fatalf("expected a single position at 4:1, actual %v", positions)
+	}
+}
+
+func TestFormatWithOptionsResolveConflicts(t *testing.T) {
+	t.Parallel()
+
+	const original = `module example.com/foo/bar
+
+go 1.23.0
+
+require (
+	example.com/a/a v1.1.1
+<<<<<<< HEAD
+	example.com/b/b v1.2.0 // ours
+	example.com/c/c v1.0.0
+||||||| base
+	example.com/b/b v1.1.0
+=======
+	example.com/b/b v1.3.0 // theirs
+	example.com/c/c v1.0.0
+	example.com/d/d v1.0.0 // indirect
+>>>>>>> feature
+)
+
+<<<<<<< HEAD
+replace example.com/e/e => example.com/f/f v1.0.0
+exclude example.com/g/g v1.0.0
+=======
+replace example.com/e/e => example.com/f/f v1.1.0
+exclude example.com/g/g v1.1.0
+>>>>>>> feature
+`
+
+	const expected = `module example.com/foo/bar
+
+go 1.23.0
+
+require (
+	example.com/a/a v1.1.1
+	// theirs
+	// ours
+	example.com/b/b v1.3.0
+	example.com/c/c v1.0.0
+)
+
+require (
+	example.com/d/d v1.0.0 // indirect
+)
+
+exclude (
+	example.com/g/g v1.0.0
+	example.com/g/g v1.1.0
+)
+
+replace (
+	example.com/e/e => example.com/f/f v1.1.0
+)
+`
+
+	actual, err := modfmt.FormatWithOptions("go.mod", []byte(original), modfmt.Options{ResolveConflicts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(actual) != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
+
+func TestFormatWithOptionsResolveConflictsError(t *testing.T) {
+	t.Parallel()
+
+	const original = `module example.com/foo/bar
+
+<<<<<<< HEAD
+retract v1.0.0
+
+replace example.com/a/a => ../a
+=======
+retract v1.1.0
+
+replace example.com/a/a => ../b
+>>>>>>> feature
+`
+
+	const expected = "go.mod:4: retract: cannot merge conflicting directive\n" +
+		"go.mod:8: retract: cannot merge conflicting directive\n" +
+		"go.mod:10: replace example.com/a/a: conflicting replacements ../a and ../b"
+
+	_, err := modfmt.FormatWithOptions("go.mod", []byte(original), modfmt.Options{ResolveConflicts: true})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if err.Error() != expected {
+		t.Fatalf("expected error %q, actual %q", expected, err.Error())
+	}
+}
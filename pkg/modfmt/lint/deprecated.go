@@ -0,0 +1,66 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package lint
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// DeprecatedDependency reports any module used by a `go.work` file which
+// requires another module in the same workspace that has been deprecated with
+// a `// Deprecated:` comment. Modules are read from disk, relative to the
+// `go.work` file, and any which cannot be read are ignored.
+type DeprecatedDependency struct{}
+
+// Name implements Rule.
+func (DeprecatedDependency) Name() string {
+	return "deprecated-dependency"
+}
+
+// Mod implements Rule. A `go.mod` file is only checked as part of a workspace.
+func (DeprecatedDependency) Mod(*modfile.File) []modfmt.Diagnostic {
+	return nil
+}
+
+// Work implements Rule.
+func (DeprecatedDependency) Work(file *modfile.WorkFile) []modfmt.Diagnostic {
+	var (
+		results    []modfmt.Diagnostic
+		members    []member
+		deprecated = make(map[string]string)
+		base       = filepath.Dir(file.Syntax.Name)
+	)
+
+	for _, use := range file.Use {
+		dir := memberDir(base, use)
+
+		mod, err := readMember(dir)
+		if err != nil || mod.Module == nil {
+			continue
+		}
+
+		if mod.Module.Deprecated != "" {
+			deprecated[mod.Module.Mod.Path] = strings.ReplaceAll(mod.Module.Deprecated, "\n", " ")
+		}
+
+		members = append(members, member{use: use, dir: dir, file: mod})
+	}
+
+	for _, member := range members {
+		for _, directive := range member.file.Require {
+			if message, ok := deprecated[directive.Mod.Path]; ok {
+				results = append(results, diagnostic(member.use.Syntax, "module %s in directory %s requires %s, which is deprecated: %s", member.file.Module.Mod.Path, member.use.Path, directive.Mod.Path, message)) //nolint:lll
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,215 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package lint
+
+import (
+	"fmt"
+	"go/version"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// godebugSetting describes a single known GODEBUG setting.
+type godebugSetting struct {
+	// name is the name of the setting, such as `panicnil`.
+	name string
+
+	// added is the Go version which introduced the setting.
+	added string
+
+	// backports are any earlier Go versions which the setting was backported
+	// to, within their own release series.
+	backports []string
+
+	// removed is the Go version which removed the setting, if any.
+	removed string
+
+	// valid reports if the given value is valid for the setting.
+	valid func(string) bool
+
+	// old reports if the given value restores behavior which is no longer
+	// supported once the setting has been removed.
+	old func(string) bool
+}
+
+// godebugSettings are every known GODEBUG setting, including settings that
+// have since been removed, sorted by name.
+//
+// See https://go.dev/doc/godebug
+var godebugSettings = []godebugSetting{
+	{name: "allowmultiplevcs", added: "1.25", backports: []string{"1.24.5", "1.23.11"}, valid: oneOf("0", "1")},
+	{name: "asynctimerchan", added: "1.23", removed: "1.27", valid: oneOf("0", "1", "2"), old: oneOf("1", "2")},
+	{name: "containermaxprocs", added: "1.25", valid: oneOf("0", "1")},
+	{name: "cryptocustomrand", added: "1.26", valid: oneOf("0", "1")},
+	{name: "dataindependenttiming", added: "1.24", valid: oneOf("0", "1")},
+	{name: "decoratemappings", added: "1.25", valid: oneOf("0", "1")},
+	{name: "embedfollowsymlinks", added: "1.25", valid: oneOf("0", "1")},
+	{name: "execerrdot", added: "1.19", valid: oneOf("0", "1")},
+	{name: "fips140", added: "1.24", valid: oneOf("off", "on", "only", "debug")},
+	{name: "fips140ems", added: "1.27", backports: []string{"1.26.6", "1.25.13"}, valid: oneOf("0", "1")},
+	{name: "gocachehash", added: "1.10", valid: oneOf("0", "1")},
+	{name: "gocachetest", added: "1.10", valid: oneOf("0", "1")},
+	{name: "gocacheverify", added: "1.10", valid: oneOf("0", "1")},
+	{name: "gotestjsonbuildtext", added: "1.24", valid: oneOf("0", "1")},
+	{name: "gotypesalias", added: "1.22", removed: "1.27", valid: oneOf("0", "1"), old: oneOf("0")},
+	{name: "htmlmetacontenturlescape", added: "1.27", backports: []string{"1.26.1", "1.25.8"}, valid: oneOf("0", "1")},
+	{name: "http2client", added: "1.6", valid: oneOf("0", "1")},
+	{name: "http2debug", added: "1.6", valid: oneOf("0", "1", "2")},
+	{name: "http2server", added: "1.6", valid: oneOf("0", "1")},
+	{name: "httpcookiemaxnum", added: "1.26", backports: []string{"1.25.2", "1.24.8"}, valid: number},
+	{name: "httplaxcontentlength", added: "1.22", valid: oneOf("0", "1")},
+	{name: "httpmuxgo121", added: "1.22", valid: oneOf("0", "1")},
+	{name: "httpservecontentkeepheaders", added: "1.23", valid: oneOf("0", "1")},
+	{name: "installgoroot", added: "1.20", valid: oneOf("all")},
+	{name: "jstmpllitinterp", added: "1.21", backports: []string{"1.20.3", "1.19.8"}, valid: oneOf("0", "1")},
+	{name: "multipartfiles", added: "1.21", backports: []string{"1.20.1", "1.19.6"}, valid: oneOf("distinct")},
+	{name: "multipartmaxheaders", added: "1.21", backports: []string{"1.20.3", "1.19.8"}, valid: number},
+	{name: "multipartmaxparts", added: "1.21", backports: []string{"1.20.3", "1.19.8"}, valid: number},
+	{name: "multipathtcp", added: "1.21", valid: oneOf("0", "1", "2", "3")},
+	{name: "netdns", added: "1.5", valid: netdns},
+	{name: "netedns0", added: "1.19", valid: oneOf("0", "1")},
+	{name: "panicnil", added: "1.21", valid: oneOf("0", "1")},
+	{name: "randautoseed", added: "1.20", valid: oneOf("0", "1")},
+	{name: "randseednop", added: "1.24", valid: oneOf("0", "1")},
+	{name: "rsa1024min", added: "1.24", valid: oneOf("0", "1")},
+	{name: "tarinsecurepath", added: "1.20", valid: oneOf("0", "1")},
+	{name: "tls10server", added: "1.22", removed: "1.27", valid: oneOf("0", "1"), old: oneOf("1")},
+	{name: "tls3des", added: "1.23", removed: "1.27", valid: oneOf("0", "1"), old: oneOf("1")},
+	{name: "tlskyber", added: "1.23", removed: "1.24", valid: oneOf("0", "1"), old: oneOf("0")},
+	{name: "tlsmaxrsasize", added: "1.22", backports: []string{"1.21.1", "1.20.8", "1.19.13"}, valid: number},
+	{name: "tlsmlkem", added: "1.24", valid: oneOf("0", "1")},
+	{name: "tlsrsakex", added: "1.22", removed: "1.27", valid: oneOf("0", "1"), old: oneOf("1")},
+	{name: "tlssecpmlkem", added: "1.26", valid: oneOf("0", "1")},
+	{name: "tlssha1", added: "1.25", valid: oneOf("0", "1")},
+	{name: "tlsunsafeekm", added: "1.22", removed: "1.27", valid: oneOf("0", "1"), old: oneOf("1")},
+	{name: "tracebacklabels", added: "1.26", valid: oneOf("0", "1")},
+	{name: "updatemaxprocs", added: "1.25", valid: oneOf("0", "1")},
+	{name: "urlmaxqueryparams", added: "1.26", backports: []string{"1.25.6", "1.24.12"}, valid: number},
+	{name: "urlstrictcolons", added: "1.26", valid: oneOf("0", "1")},
+	{name: "winreadlinkvolume", added: "1.23", valid: oneOf("0", "1")},
+	{name: "winsymlink", added: "1.23", valid: oneOf("0", "1")},
+	{name: "x509keypairleaf", added: "1.23", removed: "1.27", valid: oneOf("0", "1"), old: oneOf("0")},
+	{name: "x509negativeserial", added: "1.23", valid: oneOf("0", "1")},
+	{name: "x509rsacrt", added: "1.24", valid: oneOf("0", "1")},
+	{name: "x509sha1", added: "1.18", removed: "1.24", valid: oneOf("0", "1"), old: oneOf("1")},
+	{name: "x509sha256skid", added: "1.25", valid: oneOf("0", "1")},
+	{name: "x509sslcertoverrideplatform", added: "1.27", valid: oneOf("0", "1")},
+	{name: "x509usefallbackroots", added: "1.20", valid: oneOf("0", "1")},
+	{name: "x509usepolicies", added: "1.22", valid: oneOf("0", "1")},
+	{name: "zipinsecurepath", added: "1.20", valid: oneOf("0", "1")},
+}
+
+// lookupGodebug returns the known setting with the given name.
+func lookupGodebug(name string) (godebugSetting, bool) {
+	index, ok := slices.BinarySearchFunc(godebugSettings, name, func(setting godebugSetting, name string) int {
+		return strings.Compare(setting.name, name)
+	})
+	if !ok {
+		return godebugSetting{}, false
+	}
+
+	return godebugSettings[index], true
+}
+
+// availableIn reports if the setting is available in the given Go version,
+// taking any backports into account.
+func (s godebugSetting) availableIn(goVersion string) bool {
+	if version.Compare(goVersion, "go"+s.added) >= 0 {
+		return true
+	}
+
+	for _, backport := range s.backports {
+		if version.Lang(goVersion) == version.Lang("go"+backport) && version.Compare(goVersion, "go"+backport) >= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// oneOf returns a function which reports if a value is any of the given
+// values.
+func oneOf(values ...string) func(string) bool {
+	return func(value string) bool {
+		return slices.Contains(values, value)
+	}
+}
+
+// number reports if the given value is a non-negative integer.
+func number(value string) bool {
+	_, err := strconv.ParseUint(value, 10, 64)
+
+	return err == nil
+}
+
+// netdns reports if the given value is a valid `netdns` setting, which is a
+// resolver, a debug level, or both joined by a `+`.
+func netdns(value string) bool {
+	first, second, ok := strings.Cut(value, "+")
+	if !ok {
+		return oneOf("go", "cgo", "1", "2")(first)
+	}
+
+	resolver, debug := oneOf("go", "cgo"), oneOf("1", "2")
+
+	return (resolver(first) && debug(second)) || (debug(first) && resolver(second))
+}
+
+// checkGodebugs reports any of the given directives with an unknown key or
+// an invalid value. If a `go` directive is given, then any settings which
+// are not available in that version of Go are also reported.
+func checkGodebugs(directives []*modfile.Godebug, goDirective *modfile.Go) []modfmt.Diagnostic {
+	var results []modfmt.Diagnostic
+
+	for _, directive := range directives {
+		if message := checkGodebug(directive.Key, directive.Value, goDirective); message != "" {
+			results = append(results, diagnostic(directive.Syntax, "%s", message))
+		}
+	}
+
+	return results
+}
+
+// checkGodebug describes the problem with the given godebug key and value, or
+// returns an empty string if there is none.
+func checkGodebug(key, value string, goDirective *modfile.Go) string {
+	if key == "default" {
+		if !strings.HasPrefix(value, "go") || !modfile.GoVersionRE.MatchString(value[len("go"):]) {
+			return fmt.Sprintf("invalid value %q for godebug setting %q, must be a Go version such as go1.21", value, key)
+		}
+
+		return ""
+	}
+
+	setting, ok := lookupGodebug(key)
+
+	switch {
+	case !ok:
+		return fmt.Sprintf("unknown godebug setting %q", key)
+	case setting.removed != "" && setting.old(value):
+		return fmt.Sprintf("godebug setting %q was removed in Go %s, and can no longer be set to %q", key, setting.removed, value) //nolint:lll
+	case !setting.valid(value):
+		return fmt.Sprintf("invalid value %q for godebug setting %q", value, key)
+	case goDirective == nil:
+		return ""
+	}
+
+	goVersion := "go" + goDirective.Version
+
+	switch {
+	case !setting.availableIn(goVersion):
+		return fmt.Sprintf("godebug setting %q was added in Go %s, but the go directive is %s", key, setting.added, goDirective.Version) //nolint:lll
+	case setting.removed != "" && version.Compare(goVersion, "go"+setting.removed) >= 0:
+		return fmt.Sprintf("godebug setting %q was removed in Go %s, and has no effect", key, setting.removed)
+	}
+
+	return ""
+}
@@ -0,0 +1,63 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package lint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestGodebugSettingsTable cross-checks the table of known GODEBUG settings
+// against the table in the Go source tree, so that the two cannot drift.
+// Settings which are commented out in the Go source tree are still accepted
+// by some releases, and so must be known too.
+func TestGodebugSettingsTable(t *testing.T) {
+	t.Parallel()
+
+	if !slices.IsSortedFunc(godebugSettings, func(a, b godebugSetting) int {
+		return strings.Compare(a.name, b.name)
+	}) {
+		t.Fatal("godebug settings are not sorted by name")
+	}
+
+	output, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		t.Skipf("could not find GOROOT: %v", err)
+	}
+
+	filename := filepath.Join(strings.TrimSpace(string(output)), "src", "internal", "godebugs", "table.go")
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Skipf("could not read GODEBUG table: %v", err)
+	}
+
+	names := regexp.MustCompile(`\{Name: "([^"]+)"`).FindAllStringSubmatch(string(data), -1)
+	if len(names) == 0 {
+		t.Fatalf("found no settings in %s", filename)
+	}
+
+	upstream := make(map[string]bool, len(names))
+
+	for _, match := range names {
+		upstream[match[1]] = true
+
+		if _, ok := lookupGodebug(match[1]); !ok {
+			t.Errorf("setting %q from %s is missing", match[1], filename)
+		}
+	}
+
+	for _, setting := range godebugSettings {
+		if !upstream[setting.name] && setting.removed == "" {
+			t.Errorf("setting %q is not in %s, and is not marked as removed", setting.name, filename)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+// Package lint contains rules for enforcing policies on `go.mod` and `go.work`
+// files.
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// Rule is a single policy which can be checked against `go.mod` and `go.work`
+// files.
+type Rule interface {
+	// Name returns the unique name of the rule (e.g.`no-exclude`).
+	Name() string
+
+	// Mod checks the given `go.mod` file, and returns a diagnostic for each
+	// violation of the rule.
+	Mod(file *modfile.File) []modfmt.Diagnostic
+
+	// Work checks the given `go.work` file, and returns a diagnostic for each
+	// violation of the rule.
+	Work(file *modfile.WorkFile) []modfmt.Diagnostic
+}
+
+// Diagnostic is a violation of a specific rule.
+type Diagnostic struct {
+	modfmt.Diagnostic
+
+	// Rule is the name of the rule that was violated.
+	Rule string
+}
+
+// String returns the diagnostic in the form `file:line:column: rule: message`.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.Filename, d.Pos.Line, max(1, d.Pos.LineRune), d.Rule, d.Message)
+}
+
+// Lint parses the given data as either a `go.mod` or `go.work` file, and
+// checks it against each of the given rules. The kind of file is decided in
+// the same way as modfmt.Format, by first parsing the data as the kind of
+// file suggested by the given file name, and then as the other kind.
+func Lint(file string, data []byte, rules ...Rule) ([]Diagnostic, error) {
+	mod, work, err := modfmt.Parse(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if work != nil {
+		return Work(work, rules...), nil
+	}
+
+	return Mod(mod, rules...), nil
+}
+
+// Mod checks the given `go.mod` file against each of the given rules.
+// Diagnostics are sorted by their position.
+func Mod(file *modfile.File, rules ...Rule) []Diagnostic {
+	var results []Diagnostic
+
+	for _, rule := range rules {
+		results = appendDiagnostics(results, file.Syntax.Name, rule.Name(), rule.Mod(file))
+	}
+
+	return sortDiagnostics(results)
+}
+
+// Work checks the given `go.work` file against each of the given rules.
+// Diagnostics are sorted by their position.
+func Work(file *modfile.WorkFile, rules ...Rule) []Diagnostic {
+	var results []Diagnostic
+
+	for _, rule := range rules {
+		results = appendDiagnostics(results, file.Syntax.Name, rule.Name(), rule.Work(file))
+	}
+
+	return sortDiagnostics(results)
+}
+
+// appendDiagnostics appends the given diagnostics for the named rule, filling
+// in the file name of any diagnostic which lacks one.
+func appendDiagnostics(results []Diagnostic, filename, rule string, diagnostics []modfmt.Diagnostic) []Diagnostic {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Filename == "" {
+			diagnostic.Filename = filename
+		}
+
+		results = append(results, Diagnostic{Diagnostic: diagnostic, Rule: rule})
+	}
+
+	return results
+}
+
+// sortDiagnostics sorts the given diagnostics by their position, and then by
+// rule name.
+func sortDiagnostics(diagnostics []Diagnostic) []Diagnostic {
+	slices.SortStableFunc(diagnostics, func(a, b Diagnostic) int {
+		if c := cmp.Compare(a.Pos.Byte, b.Pos.Byte); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Rule, b.Rule)
+	})
+
+	return diagnostics
+}
+
+// DefaultRules returns the built-in rules which are checked by default. These
+// rules report mistakes, such as unknown GODEBUG settings, rather than
+// enforcing a policy. The remaining built-in rules enforce policies which are
+// valid Go, but do not suit every project, and must be chosen explicitly.
+func DefaultRules() []Rule {
+	return []Rule{
+		DeprecatedDependency{},
+		KnownGodebug{},
+		Workspace{},
+	}
+}
+
+// Rules returns every built-in rule.
+func Rules() []Rule {
+	return []Rule{
+		DeprecatedDependency{},
+		KnownGodebug{},
+		NoExclude{},
+		NoLocalReplace{},
+		RequireToolchain{},
+		RetractRationale{},
+		Workspace{},
+	}
+}
+
+// Lookup returns the built-in rule with the given name.
+func Lookup(name string) (Rule, bool) {
+	for _, rule := range Rules() {
+		if rule.Name() == name {
+			return rule, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,211 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package lint_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/joshdk/modfmt/pkg/modfmt/lint"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		expected []string
+	}{
+		{
+			name:     "mod",
+			filename: "go.mod",
+			data: `module example.com/foo/bar
+
+go 1.23.0
+
+godebug (
+	panicnil=1
+	notarealsetting=1
+)
+
+exclude example.com/a/a v1.0.0
+
+replace example.com/b/b => ../b
+replace example.com/c/c => example.com/d/d v1.0.0
+`,
+			expected: []string{
+				"go.mod:3:1: require-toolchain: toolchain directive is missing",
+				"go.mod:7:2: known-godebug: unknown godebug setting \"notarealsetting\"",
+				"go.mod:10:1: no-exclude: exclude of example.com/a/a v1.0.0 is not allowed",
+				"go.mod:12:1: no-local-replace: local replacement of example.com/b/b with ../b is not allowed",
+			},
+		},
+		{
+			name:     "godebug",
+			filename: "go.mod",
+			data: `module example.com/foo/bar
+
+go 1.24.9
+
+toolchain go1.24.9
+
+godebug (
+	default=go1.21
+	default=1.21
+	httpmuxgo212=1
+	panicnil=yes
+	netdns=go+2
+	netdns=cgo+go
+	fips140=only
+	multipartmaxparts=2000
+	tlsmaxrsasize=-1
+	httpcookiemaxnum=3000
+	urlmaxqueryparams=100
+	urlstrictcolons=0
+	x509sha1=0
+	x509sha1=1
+	tls10server=0
+	multipartfiles=distinct
+)
+`,
+			expected: []string{
+				"go.mod:9:2: known-godebug: invalid value \"1.21\" for godebug setting \"default\", must be a Go version such as go1.21", //nolint:lll
+				"go.mod:10:2: known-godebug: unknown godebug setting \"httpmuxgo212\"",
+				"go.mod:11:2: known-godebug: invalid value \"yes\" for godebug setting \"panicnil\"",
+				"go.mod:13:2: known-godebug: invalid value \"cgo+go\" for godebug setting \"netdns\"",
+				"go.mod:16:2: known-godebug: invalid value \"-1\" for godebug setting \"tlsmaxrsasize\"",
+				"go.mod:18:2: known-godebug: godebug setting \"urlmaxqueryparams\" was added in Go 1.26, but the go directive is 1.24.9", //nolint:lll
+				"go.mod:19:2: known-godebug: godebug setting \"urlstrictcolons\" was added in Go 1.26, but the go directive is 1.24.9",   //nolint:lll
+				"go.mod:20:2: known-godebug: godebug setting \"x509sha1\" was removed in Go 1.24, and has no effect",
+				"go.mod:21:2: known-godebug: godebug setting \"x509sha1\" was removed in Go 1.24, and can no longer be set to \"1\"", //nolint:lll
+			},
+		},
+		{
+			name:     "retract",
+			filename: "go.mod",
+			data: `module example.com/foo/bar
+
+go 1.23.0
+
+toolchain go1.23.4
+
+// Published accidentally.
+retract v1.0.0
+
+retract (
+	v1.1.0 // Contains a security vulnerability.
+	v1.2.0
+	[v1.3.0, v1.3.5]
+	//
+	v1.4.0
+)
+`,
+			expected: []string{
+				"go.mod:12:2: retract-rationale: retraction of v1.2.0 has no rationale",
+				"go.mod:13:2: retract-rationale: retraction of [v1.3.0, v1.3.5] has no rationale",
+				"go.mod:15:2: retract-rationale: retraction of v1.4.0 has no rationale",
+			},
+		},
+		{
+			name:     "work",
+			filename: "go.work",
+			data: `go 1.23.0
+
+toolchain go1.23.4
+
+use ./a
+
+replace example.com/b/b => ../b
+`,
+			expected: []string{
+				"go.work:5:1: workspace: directory ./a does not exist",
+			},
+		},
+		{
+			name:     "deprecated",
+			filename: "testdata/deprecated/go.work",
+			data: `go 1.23.0
+
+toolchain go1.23.4
+
+use (
+	./a
+	./b
+	./c
+)
+`,
+			expected: []string{
+				"testdata/deprecated/go.work:7:2: deprecated-dependency: module example.com/b in directory ./b requires example.com/a, which is deprecated: use example.com/c instead.", //nolint:lll
+			},
+		},
+		{
+			name:     "renamed work",
+			filename: "renamed",
+			data: `go 1.23.0
+
+toolchain go1.23.4
+
+use ./a
+`,
+			expected: []string{
+				"renamed:5:1: workspace: directory ./a does not exist",
+			},
+		},
+		{
+			name:     "workspace",
+			filename: "testdata/workspace/go.work",
+			data: `go 1.24.0
+
+toolchain go1.24.0
+
+use (
+	./a
+	./b
+	./c
+	./d
+	./e
+)
+
+replace example.com/x => ./x
+replace example.com/y => example.com/w v1.0.0
+replace example.com/t v1.1.0 => example.com/s v1.1.0
+replace example.com/v v1.0.0 => example.com/u v1.0.0
+`,
+			expected: []string{
+				"testdata/workspace/go.work:7:2: workspace: module example.com/a in directory ./b is already used from directory ./a",          //nolint:lll
+				"testdata/workspace/go.work:7:2: workspace: module example.com/a requires go 1.25.0, but the workspace go directive is 1.24.0", //nolint:lll
+				"testdata/workspace/go.work:8:2: workspace: directory ./c does not exist",
+				"testdata/workspace/go.work:9:2: workspace: directory ./d does not contain a go.mod file",
+				"testdata/workspace/go.work:13:1: workspace: replacement of example.com/x shadows a conflicting replacement with ../../x in directory ./b",              //nolint:lll
+				"testdata/workspace/go.work:14:1: workspace: replacement of example.com/y shadows a conflicting replacement with example.com/z v1.0.0 in directory ./a", //nolint:lll
+				"testdata/workspace/go.work:14:1: workspace: replacement of example.com/y shadows a conflicting replacement with example.com/z v1.0.0 in directory ./e", //nolint:lll
+				"testdata/workspace/go.work:16:1: workspace: replacement of example.com/v shadows a conflicting replacement with ../v in directory ./e",                 //nolint:lll
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			diagnostics, err := lint.Lint(test.filename, []byte(test.data), lint.Rules()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var actual []string
+			for _, diagnostic := range diagnostics {
+				actual = append(actual, diagnostic.String())
+			}
+
+			if !slices.Equal(test.expected, actual) {
+				t.Fatalf("expected:\n%q\nactual:\n%q", test.expected, actual)
+			}
+		})
+	}
+}
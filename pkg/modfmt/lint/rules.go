@@ -0,0 +1,183 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// diagnostic returns a diagnostic positioned at the start of the given line.
+func diagnostic(line *modfile.Line, format string, args ...any) modfmt.Diagnostic {
+	return modfmt.Diagnostic{
+		Pos:     line.Start,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// firstLine returns the first of the given lines which is non-nil, or a line
+// at the start of the file if all are nil.
+func firstLine(lines ...*modfile.Line) *modfile.Line {
+	for _, line := range lines {
+		if line != nil {
+			return line
+		}
+	}
+
+	return &modfile.Line{Start: modfile.Position{Line: 1, LineRune: 1}}
+}
+
+// NoExclude reports every `exclude` directive.
+type NoExclude struct{}
+
+// Name implements Rule.
+func (NoExclude) Name() string {
+	return "no-exclude"
+}
+
+// Mod implements Rule.
+func (NoExclude) Mod(file *modfile.File) []modfmt.Diagnostic {
+	var results []modfmt.Diagnostic
+
+	for _, directive := range file.Exclude {
+		results = append(results, diagnostic(directive.Syntax, "exclude of %s %s is not allowed", directive.Mod.Path, directive.Mod.Version)) //nolint:lll
+	}
+
+	return results
+}
+
+// Work implements Rule. A `go.work` file cannot contain `exclude` directives.
+func (NoExclude) Work(*modfile.WorkFile) []modfmt.Diagnostic {
+	return nil
+}
+
+// NoLocalReplace reports every `replace` directive in a `go.mod` file which
+// replaces a module with a local directory. Local replacements in `go.work`
+// files are allowed, since that is their purpose.
+type NoLocalReplace struct{}
+
+// Name implements Rule.
+func (NoLocalReplace) Name() string {
+	return "no-local-replace"
+}
+
+// Mod implements Rule.
+func (NoLocalReplace) Mod(file *modfile.File) []modfmt.Diagnostic {
+	var results []modfmt.Diagnostic
+
+	for _, directive := range file.Replace {
+		if directive.New.Version == "" {
+			results = append(results, diagnostic(directive.Syntax, "local replacement of %s with %s is not allowed", directive.Old.Path, directive.New.Path)) //nolint:lll
+		}
+	}
+
+	return results
+}
+
+// Work implements Rule.
+func (NoLocalReplace) Work(*modfile.WorkFile) []modfmt.Diagnostic {
+	return nil
+}
+
+// RetractRationale reports every `retract` directive without a rationale,
+// which is shown by `go list -m -retracted` and `go get` to explain why a
+// version was retracted.
+type RetractRationale struct{}
+
+// Name implements Rule.
+func (RetractRationale) Name() string {
+	return "retract-rationale"
+}
+
+// Mod implements Rule.
+func (RetractRationale) Mod(file *modfile.File) []modfmt.Diagnostic {
+	var results []modfmt.Diagnostic
+
+	for _, directive := range file.Retract {
+		if strings.TrimSpace(directive.Rationale) != "" {
+			continue
+		}
+
+		versions := directive.Low
+		if directive.Low != directive.High {
+			versions = "[" + directive.Low + ", " + directive.High + "]"
+		}
+
+		results = append(results, diagnostic(directive.Syntax, "retraction of %s has no rationale", versions))
+	}
+
+	return results
+}
+
+// Work implements Rule. A `go.work` file cannot contain `retract` directives.
+func (RetractRationale) Work(*modfile.WorkFile) []modfmt.Diagnostic {
+	return nil
+}
+
+// RequireToolchain reports any file without a `toolchain` directive.
+type RequireToolchain struct{}
+
+// Name implements Rule.
+func (RequireToolchain) Name() string {
+	return "require-toolchain"
+}
+
+// Mod implements Rule.
+func (RequireToolchain) Mod(file *modfile.File) []modfmt.Diagnostic {
+	if file.Toolchain != nil {
+		return nil
+	}
+
+	var goLine, moduleLine *modfile.Line
+	if file.Go != nil {
+		goLine = file.Go.Syntax
+	}
+
+	if file.Module != nil {
+		moduleLine = file.Module.Syntax
+	}
+
+	return []modfmt.Diagnostic{diagnostic(firstLine(goLine, moduleLine), "toolchain directive is missing")}
+}
+
+// Work implements Rule.
+func (RequireToolchain) Work(file *modfile.WorkFile) []modfmt.Diagnostic {
+	if file.Toolchain != nil {
+		return nil
+	}
+
+	var goLine *modfile.Line
+	if file.Go != nil {
+		goLine = file.Go.Syntax
+	}
+
+	return []modfmt.Diagnostic{diagnostic(firstLine(goLine), "toolchain directive is missing")}
+}
+
+// KnownGodebug reports any `godebug` directive with a key that is not a known
+// GODEBUG setting, or with a value that is not valid for that setting. Any
+// settings which were added after, or removed before, the version of Go in the
+// `go` directive are also reported.
+type KnownGodebug struct{}
+
+// Name implements Rule.
+func (KnownGodebug) Name() string {
+	return "known-godebug"
+}
+
+// Mod implements Rule.
+func (KnownGodebug) Mod(file *modfile.File) []modfmt.Diagnostic {
+	return checkGodebugs(file.Godebug, file.Go)
+}
+
+// Work implements Rule.
+func (KnownGodebug) Work(file *modfile.WorkFile) []modfmt.Diagnostic {
+	return checkGodebugs(file.Godebug, file.Go)
+}
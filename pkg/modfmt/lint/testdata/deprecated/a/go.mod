@@ -0,0 +1,4 @@
+// Deprecated: use example.com/c instead.
+module example.com/a
+
+go 1.23.0
@@ -0,0 +1,8 @@
+module example.com/b
+
+go 1.23.0
+
+require (
+	example.com/a v1.0.0
+	example.com/c v1.0.0
+)
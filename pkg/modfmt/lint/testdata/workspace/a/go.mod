@@ -0,0 +1,11 @@
+module example.com/a
+
+go 1.23.0
+
+replace (
+	example.com/y v1.0.0 => example.com/z v1.0.0
+)
+
+replace (
+	example.com/x => ../x
+)
@@ -0,0 +1,7 @@
+module example.com/a
+
+go 1.25.0
+
+replace (
+	example.com/x => ../../x
+)
@@ -0,0 +1,12 @@
+module example.com/e
+
+go 1.21
+
+replace (
+	example.com/t v1.0.0 => example.com/s v1.0.0
+	example.com/y v1.0.0 => example.com/z v1.0.0
+)
+
+replace (
+	example.com/v => ../v
+)
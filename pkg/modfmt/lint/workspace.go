@@ -0,0 +1,181 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package lint
+
+import (
+	"errors"
+	"go/version"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+// Workspace reports any `go.work` file which is inconsistent with the `go.mod`
+// files of the modules that it uses. Every `use` directory must exist and
+// contain a `go.mod` file, no module may require a newer version of Go than
+// the workspace, no two modules may have the same module path, and no
+// `replace` directive may shadow a conflicting `replace` directive in any of
+// the modules. Modules are read from disk, relative to the `go.work` file.
+type Workspace struct{}
+
+// Name implements Rule.
+func (Workspace) Name() string {
+	return "workspace"
+}
+
+// Mod implements Rule. A `go.mod` file is only checked as part of a workspace.
+func (Workspace) Mod(*modfile.File) []modfmt.Diagnostic {
+	return nil
+}
+
+// member is a module used by a workspace.
+type member struct {
+	// use is the directive which uses the module.
+	use *modfile.Use
+
+	// dir is the directory of the module.
+	dir string
+
+	// file is the parsed `go.mod` file of the module.
+	file *modfile.File
+}
+
+// Work implements Rule.
+func (Workspace) Work(file *modfile.WorkFile) []modfmt.Diagnostic {
+	var (
+		results []modfmt.Diagnostic
+		members []member
+		paths   = make(map[string]*modfile.Use)
+		base    = filepath.Dir(file.Syntax.Name)
+	)
+
+	for _, use := range file.Use {
+		dir := memberDir(base, use)
+
+		mod, err := readMember(dir)
+
+		switch {
+		case errors.Is(err, errNoDirectory):
+			results = append(results, diagnostic(use.Syntax, "directory %s does not exist", use.Path))
+
+			continue
+		case errors.Is(err, fs.ErrNotExist):
+			results = append(results, diagnostic(use.Syntax, "directory %s does not contain a go.mod file", use.Path))
+
+			continue
+		case err != nil:
+			results = append(results, diagnostic(use.Syntax, "%v", err))
+
+			continue
+		}
+
+		if mod.Module == nil {
+			results = append(results, diagnostic(use.Syntax, "directory %s has a go.mod file without a module directive", use.Path)) //nolint:lll
+
+			continue
+		}
+
+		path := mod.Module.Mod.Path
+
+		if first, ok := paths[path]; ok {
+			results = append(results, diagnostic(use.Syntax, "module %s in directory %s is already used from directory %s", path, use.Path, first.Path)) //nolint:lll
+		} else {
+			paths[path] = use
+		}
+
+		if file.Go != nil && mod.Go != nil && version.Compare("go"+mod.Go.Version, "go"+file.Go.Version) > 0 {
+			results = append(results, diagnostic(use.Syntax, "module %s requires go %s, but the workspace go directive is %s", path, mod.Go.Version, file.Go.Version)) //nolint:lll
+		}
+
+		members = append(members, member{use: use, dir: dir, file: mod})
+	}
+
+	for _, directive := range file.Replace {
+		for _, member := range members {
+			for _, shadowed := range member.file.Replace {
+				if shadowed.Old.Path != directive.Old.Path {
+					continue
+				}
+
+				// An unversioned replacement applies to every version, so only
+				// two versioned replacements can apply to different versions.
+				if directive.Old.Version != "" && shadowed.Old.Version != "" && directive.Old.Version != shadowed.Old.Version {
+					continue
+				}
+
+				if replaceTarget(base, directive.New) == replaceTarget(member.dir, shadowed.New) {
+					continue
+				}
+
+				results = append(results, diagnostic(directive.Syntax, "replacement of %s shadows a conflicting replacement with %s in directory %s", directive.Old.Path, targetString(shadowed.New), member.use.Path)) //nolint:lll
+			}
+		}
+	}
+
+	return results
+}
+
+// memberDir returns the directory of the module used by the given directive,
+// relative to the given directory, which contains the `go.work` file.
+func memberDir(base string, use *modfile.Use) string {
+	dir := filepath.FromSlash(use.Path)
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+
+	return filepath.Join(base, dir)
+}
+
+// errNoDirectory is returned when a module directory does not exist.
+var errNoDirectory = errors.New("directory does not exist")
+
+// readMember reads and parses the `go.mod` file in the given module
+// directory.
+func readMember(dir string) (*modfile.File, error) {
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return nil, errNoDirectory
+	}
+
+	filename := filepath.Join(dir, "go.mod")
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return modfile.Parse(filename, data, nil)
+}
+
+// replaceTarget returns a comparable form of the given replacement target.
+// Local directories are made relative to the given directory, which contains
+// the file with the replacement.
+func replaceTarget(dir string, target module.Version) string {
+	if target.Version != "" {
+		return target.Path + "@" + target.Version
+	}
+
+	path := filepath.FromSlash(target.Path)
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// targetString returns the given replacement target as it would be written
+// in a `replace` directive.
+func targetString(target module.Version) string {
+	if target.Version != "" {
+		return target.Path + " " + target.Version
+	}
+
+	return target.Path
+}
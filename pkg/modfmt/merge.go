@@ -0,0 +1,392 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt
+
+import (
+	"bytes"
+	"errors"
+	"go/version"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// Merge performs a three-way merge of the given `go.mod` or `go.work` files,
+// where ours and theirs were both changed from base, and formats the result
+// according to the given options. Directives are merged individually, so that
+// unrelated changes to the same block of directives do not conflict. If both
+// sides changed the version of the same module, or the `go` or `toolchain`
+// version, then the highest version is kept. Any other directive that was
+// changed differently by both sides is reported as an error.
+//
+// The kind of file is guessed from ours, in the same way as Format, and the
+// other files are parsed as the same kind. An empty base is allowed, for a
+// file that was added by both sides.
+func Merge(file string, base, ours, theirs []byte, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	oursMod, oursWork, err := parse(file, ours, opts.versionFixer())
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if oursWork != nil {
+		baseWork, err := modfile.ParseWork(file, base, opts.versionFixer())
+		if err != nil {
+			return nil, err
+		}
+
+		theirsWork, err := modfile.ParseWork(file, theirs, opts.versionFixer())
+		if err != nil {
+			return nil, err
+		}
+
+		if err := mergeWork(baseWork, oursWork, theirsWork); err != nil {
+			return nil, err
+		}
+
+		formatWork(oursWork, &buf, opts)
+
+		return buf.Bytes(), nil
+	}
+
+	baseMod, err := modfile.Parse(file, base, opts.versionFixer())
+	if err != nil {
+		return nil, err
+	}
+
+	theirsMod, err := modfile.Parse(file, theirs, opts.versionFixer())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mergeMod(baseMod, oursMod, theirsMod); err != nil {
+		return nil, err
+	}
+
+	if err := formatMod(oursMod, &buf, opts); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mergeMod merges the changes from base to theirs into ours.
+func mergeMod(base, ours, theirs *modfile.File) error {
+	m := merger{filename: ours.Syntax.Name}
+
+	ours.Module = single(mergeDirectives(&m, "module",
+		optional(base.Module), optional(ours.Module), optional(theirs.Module),
+		func(*modfile.Module) string { return "" },
+		func(d *modfile.Module) string { return d.Mod.Path },
+		func(d *modfile.Module) *modfile.Line { return d.Syntax },
+		nil))
+
+	ours.Go = single(mergeDirectives(&m, "go",
+		optional(base.Go), optional(ours.Go), optional(theirs.Go),
+		func(*modfile.Go) string { return "" },
+		func(d *modfile.Go) string { return d.Version },
+		func(d *modfile.Go) *modfile.Line { return d.Syntax },
+		mergeGo))
+
+	ours.Toolchain = single(mergeDirectives(&m, "toolchain",
+		optional(base.Toolchain), optional(ours.Toolchain), optional(theirs.Toolchain),
+		func(*modfile.Toolchain) string { return "" },
+		func(d *modfile.Toolchain) string { return d.Name },
+		func(d *modfile.Toolchain) *modfile.Line { return d.Syntax },
+		mergeToolchain))
+
+	ours.Godebug = mergeDirectives(&m, "godebug", base.Godebug, ours.Godebug, theirs.Godebug,
+		func(d *modfile.Godebug) string { return d.Key },
+		func(d *modfile.Godebug) string { return d.Value },
+		func(d *modfile.Godebug) *modfile.Line { return d.Syntax },
+		nil)
+
+	ours.Retract = mergeDirectives(&m, "retract", base.Retract, ours.Retract, theirs.Retract,
+		func(d *modfile.Retract) string { return d.Low + " " + d.High },
+		func(d *modfile.Retract) string { return d.Rationale },
+		func(d *modfile.Retract) *modfile.Line { return d.Syntax },
+		nil)
+
+	ours.Require = mergeDirectives(&m, "require", base.Require, ours.Require, theirs.Require,
+		func(d *modfile.Require) string { return d.Mod.Path },
+		func(d *modfile.Require) string {
+			if d.Indirect {
+				return d.Mod.Version + " indirect"
+			}
+
+			return d.Mod.Version
+		},
+		func(d *modfile.Require) *modfile.Line { return d.Syntax },
+		mergeRequire)
+
+	ours.Exclude = mergeDirectives(&m, "exclude", base.Exclude, ours.Exclude, theirs.Exclude,
+		func(d *modfile.Exclude) string { return d.Mod.Path + " " + d.Mod.Version },
+		func(*modfile.Exclude) string { return "" },
+		func(d *modfile.Exclude) *modfile.Line { return d.Syntax },
+		nil)
+
+	ours.Replace = mergeReplaces(&m, base.Replace, ours.Replace, theirs.Replace)
+
+	ours.Ignore = mergeDirectives(&m, "ignore", base.Ignore, ours.Ignore, theirs.Ignore,
+		func(d *modfile.Ignore) string { return d.Path },
+		func(*modfile.Ignore) string { return "" },
+		func(d *modfile.Ignore) *modfile.Line { return d.Syntax },
+		nil)
+
+	ours.Tool = mergeDirectives(&m, "tool", base.Tool, ours.Tool, theirs.Tool,
+		func(d *modfile.Tool) string { return d.Path },
+		func(*modfile.Tool) string { return "" },
+		func(d *modfile.Tool) *modfile.Line { return d.Syntax },
+		nil)
+
+	return m.err()
+}
+
+// mergeWork merges the changes from base to theirs into ours.
+func mergeWork(base, ours, theirs *modfile.WorkFile) error {
+	m := merger{filename: ours.Syntax.Name}
+
+	ours.Go = single(mergeDirectives(&m, "go",
+		optional(base.Go), optional(ours.Go), optional(theirs.Go),
+		func(*modfile.Go) string { return "" },
+		func(d *modfile.Go) string { return d.Version },
+		func(d *modfile.Go) *modfile.Line { return d.Syntax },
+		mergeGo))
+
+	ours.Toolchain = single(mergeDirectives(&m, "toolchain",
+		optional(base.Toolchain), optional(ours.Toolchain), optional(theirs.Toolchain),
+		func(*modfile.Toolchain) string { return "" },
+		func(d *modfile.Toolchain) string { return d.Name },
+		func(d *modfile.Toolchain) *modfile.Line { return d.Syntax },
+		mergeToolchain))
+
+	ours.Godebug = mergeDirectives(&m, "godebug", base.Godebug, ours.Godebug, theirs.Godebug,
+		func(d *modfile.Godebug) string { return d.Key },
+		func(d *modfile.Godebug) string { return d.Value },
+		func(d *modfile.Godebug) *modfile.Line { return d.Syntax },
+		nil)
+
+	ours.Use = mergeDirectives(&m, "use", base.Use, ours.Use, theirs.Use,
+		func(d *modfile.Use) string { return d.Path },
+		func(*modfile.Use) string { return "" },
+		func(d *modfile.Use) *modfile.Line { return d.Syntax },
+		nil)
+
+	ours.Replace = mergeReplaces(&m, base.Replace, ours.Replace, theirs.Replace)
+
+	return m.err()
+}
+
+// merger collects the conflicts found while merging.
+type merger struct {
+	// filename is the name of the file being merged.
+	filename string
+
+	// conflicts are the conflicts found so far.
+	conflicts modfile.ErrorList
+}
+
+// conflict records a conflict for the given directive, positioned at the given
+// line.
+func (m *merger) conflict(verb, key string, line *modfile.Line, message string) {
+	var pos modfile.Position
+	if line != nil {
+		pos = line.Start
+	}
+
+	m.conflicts = append(m.conflicts, modfile.Error{
+		Filename: m.filename,
+		Pos:      pos,
+		Verb:     verb,
+		ModPath:  key,
+		Err:      errors.New(message),
+	})
+}
+
+// err returns the conflicts found, if any.
+func (m *merger) err() error {
+	if len(m.conflicts) > 0 {
+		return m.conflicts
+	}
+
+	return nil
+}
+
+// mergeDirectives performs a three-way merge of the given directives. Each
+// directive is identified by its key, and is compared by its value. If ours
+// and theirs both changed the value of a directive differently, then the
+// given resolve function is used to pick a directive, and if it is nil or
+// cannot pick one then a conflict is recorded. Directives are returned in the
+// order of ours, followed by any added only by theirs.
+func mergeDirectives[T any](
+	m *merger,
+	verb string,
+	base, ours, theirs []T,
+	key, value func(T) string,
+	syntax func(T) *modfile.Line,
+	resolve func(ours, theirs T) (T, bool),
+) []T {
+	index := func(directives []T) map[string]T {
+		results := make(map[string]T, len(directives))
+		for _, directive := range directives {
+			if _, ok := results[key(directive)]; !ok {
+				results[key(directive)] = directive
+			}
+		}
+
+		return results
+	}
+
+	baseIndex, oursIndex, theirsIndex := index(base), index(ours), index(theirs)
+
+	// same reports if the directive with the given key is the same in
+	// both of the given indexes, including if it is missing from both.
+	same := func(a, b map[string]T, k string) bool {
+		x, xok := a[k]
+		y, yok := b[k]
+
+		return xok == yok && (!xok || value(x) == value(y))
+	}
+
+	var (
+		results []T
+		keys    []string
+	)
+
+	for _, directive := range slices.Concat(ours, theirs) {
+		if !slices.Contains(keys, key(directive)) {
+			keys = append(keys, key(directive))
+		}
+	}
+
+	for _, k := range keys {
+		o, ook := oursIndex[k]
+		t, tok := theirsIndex[k]
+
+		switch {
+		case same(oursIndex, theirsIndex, k), same(theirsIndex, baseIndex, k):
+			// Theirs made no change, or the same change as ours.
+			if ook {
+				results = append(results, o)
+			}
+		case same(oursIndex, baseIndex, k):
+			// Only theirs made a change.
+			if tok {
+				results = append(results, t)
+			}
+		case ook && tok && resolve != nil:
+			// Both made different changes.
+			if directive, ok := resolve(o, t); ok {
+				results = append(results, directive)
+
+				continue
+			}
+
+			m.conflict(verb, k, syntax(o), "changed differently on both sides")
+		case ook && tok:
+			m.conflict(verb, k, syntax(o), "changed differently on both sides")
+		case ook:
+			m.conflict(verb, k, syntax(o), "changed on one side and removed on the other")
+		default:
+			m.conflict(verb, k, syntax(t), "changed on one side and removed on the other")
+		}
+	}
+
+	return results
+}
+
+// mergeReplaces performs a three-way merge of the given replace directives.
+// If both sides replaced a module with different versions of the same module,
+// then the highest version is kept.
+func mergeReplaces(m *merger, base, ours, theirs []*modfile.Replace) []*modfile.Replace {
+	return mergeDirectives(m, "replace", base, ours, theirs,
+		func(d *modfile.Replace) string { return d.Old.Path + " " + d.Old.Version },
+		func(d *modfile.Replace) string { return d.New.Path + " " + d.New.Version },
+		func(d *modfile.Replace) *modfile.Line { return d.Syntax },
+		func(ours, theirs *modfile.Replace) (*modfile.Replace, bool) {
+			if ours.New.Path != theirs.New.Path || ours.New.Version == "" || theirs.New.Version == "" {
+				return nil, false
+			}
+
+			if semver.Compare(theirs.New.Version, ours.New.Version) > 0 {
+				return theirs, true
+			}
+
+			return ours, true
+		})
+}
+
+// mergeRequire resolves requirements which were changed differently by both
+// sides, by keeping the highest version. A module which either side requires
+// directly is kept as a direct requirement.
+func mergeRequire(ours, theirs *modfile.Require) (*modfile.Require, bool) {
+	highest := ours.Mod.Version
+	if semver.Compare(theirs.Mod.Version, highest) > 0 {
+		highest = theirs.Mod.Version
+	}
+
+	// The `// indirect` marker is part of the comments, so the directive
+	// with the right marker is kept.
+	result := ours
+	if (ours.Indirect && !theirs.Indirect) || (ours.Indirect == theirs.Indirect && highest != ours.Mod.Version) {
+		result = theirs
+	}
+
+	result.Mod.Version = highest
+
+	return result, true
+}
+
+// mergeGo resolves `go` directives which were changed differently by both
+// sides, by keeping the highest version.
+func mergeGo(ours, theirs *modfile.Go) (*modfile.Go, bool) {
+	if version.Compare("go"+theirs.Version, "go"+ours.Version) > 0 {
+		return theirs, true
+	}
+
+	return ours, true
+}
+
+// mergeToolchain resolves `toolchain` directives which were changed
+// differently by both sides, by keeping the highest version. Toolchains which
+// are not versions, such as `default`, cannot be resolved.
+func mergeToolchain(ours, theirs *modfile.Toolchain) (*modfile.Toolchain, bool) {
+	if !version.IsValid(ours.Name) || !version.IsValid(theirs.Name) {
+		return nil, false
+	}
+
+	if version.Compare(theirs.Name, ours.Name) > 0 {
+		return theirs, true
+	}
+
+	return ours, true
+}
+
+// optional returns the given directive as a slice, which is empty if the
+// directive is nil.
+func optional[T any](directive *T) []*T {
+	if directive == nil {
+		return nil
+	}
+
+	return []*T{directive}
+}
+
+// single returns the only directive in the given slice, or nil if it is
+// empty.
+func single[T any](directives []*T) *T {
+	if len(directives) == 0 {
+		return nil
+	}
+
+	return directives[0]
+}
@@ -0,0 +1,157 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt_test
+
+import (
+	"testing"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		base     string
+		ours     string
+		theirs   string
+		expected string
+		err      string
+	}{
+		{
+			name:     "mod",
+			filename: "go.mod",
+			base: `module example.com/foo/bar
+
+go 1.23.0
+
+require (
+	example.com/a/a v1.0.0
+	example.com/b/b v1.0.0
+	example.com/c/c v1.0.0
+	example.com/d/d v1.0.0 // indirect
+)
+`,
+			ours: `module example.com/foo/bar
+
+go 1.24.0
+
+require (
+	example.com/a/a v1.1.0
+	example.com/b/b v1.0.0
+	example.com/c/c v1.2.0 // ours
+	example.com/d/d v1.0.0 // indirect
+	example.com/e/e v1.0.0
+)
+`,
+			theirs: `module example.com/foo/bar
+
+go 1.23.0
+
+require (
+	example.com/a/a v1.0.0
+	example.com/b/b v1.1.0
+	example.com/c/c v1.3.0 // theirs
+	example.com/d/d v1.0.0
+	example.com/f/f v1.0.0
+)
+
+replace example.com/g/g => example.com/h/h v1.0.0
+`,
+			expected: `module example.com/foo/bar
+
+go 1.24.0
+
+require (
+	example.com/a/a v1.1.0
+	example.com/b/b v1.1.0
+	// theirs
+	example.com/c/c v1.3.0
+	example.com/d/d v1.0.0
+	example.com/e/e v1.0.0
+	example.com/f/f v1.0.0
+)
+
+replace (
+	example.com/g/g => example.com/h/h v1.0.0
+)
+`,
+		},
+		{
+			name:     "work",
+			filename: "go.work",
+			base: `go 1.23.0
+
+use ./a
+`,
+			ours: `go 1.23.0
+
+use (
+	./a
+	./b
+)
+`,
+			theirs: `go 1.23.0
+
+use (
+	./c
+)
+`,
+			expected: `go 1.23.0
+
+use (
+	./b
+	./c
+)
+`,
+		},
+		{
+			name:     "conflict",
+			filename: "go.mod",
+			base: `module example.com/foo/bar
+
+godebug panicnil=1
+
+require example.com/a/a v1.0.0
+`,
+			ours: `module example.com/foo/bar
+
+godebug panicnil=0
+`,
+			theirs: `module example.com/foo/bar
+
+godebug panicnil=2
+
+require example.com/a/a v1.1.0
+`,
+			err: "go.mod:3: godebug panicnil: changed differently on both sides\n" +
+				"go.mod:5: require example.com/a/a: changed on one side and removed on the other",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := modfmt.Merge(test.filename, []byte(test.base), []byte(test.ours), []byte(test.theirs), modfmt.Options{}) //nolint:lll
+
+			switch {
+			case test.err != "" && err == nil:
+				t.Fatal("expected an error")
+			case test.err != "" && err.Error() != test.err:
+				t.Fatalf("expected error %q, actual %q", test.err, err.Error())
+			case test.err == "" && err != nil:
+				t.Fatal(err)
+			}
+
+			if string(actual) != test.expected {
+				t.Fatalf("expected:\n%s\nactual:\n%s", test.expected, actual)
+			}
+		})
+	}
+}
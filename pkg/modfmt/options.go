@@ -0,0 +1,253 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Section identifies a section of a `go.mod` or `go.work` file.
+type Section string
+
+const (
+	// SectionHeader is the section of all header or unattached comments.
+	SectionHeader Section = "header"
+
+	// SectionModule is the `module …` section.
+	SectionModule Section = "module"
+
+	// SectionGo is the `go …` section.
+	SectionGo Section = "go"
+
+	// SectionToolchain is the `toolchain …` section.
+	SectionToolchain Section = "toolchain"
+
+	// SectionGodebug is the `godebug (…)` section.
+	SectionGodebug Section = "godebug"
+
+	// SectionRetract is the `retract (…)` section.
+	SectionRetract Section = "retract"
+
+	// SectionRequire is the `require (…)` section for direct dependencies.
+	SectionRequire Section = "require"
+
+	// SectionRequireIndirect is the `require (…)` section for indirect
+	// dependencies.
+	SectionRequireIndirect Section = "require-indirect"
+
+	// SectionIgnore is the `ignore (…)` section.
+	SectionIgnore Section = "ignore"
+
+	// SectionExclude is the `exclude (…)` section.
+	SectionExclude Section = "exclude"
+
+	// SectionReplace is the `replace (…)` section for module replacements.
+	SectionReplace Section = "replace"
+
+	// SectionReplaceLocal is the `replace (…)` section for local
+	// replacements.
+	SectionReplaceLocal Section = "replace-local"
+
+	// SectionTool is the `tool (…)` section.
+	SectionTool Section = "tool"
+
+	// SectionUse is the `use (…)` section.
+	SectionUse Section = "use"
+)
+
+// defaultModOrder is the default ordering of sections in `go.mod` files.
+var defaultModOrder = []Section{
+	SectionHeader,
+	SectionModule,
+	SectionGo,
+	SectionToolchain,
+	SectionGodebug,
+	SectionRetract,
+	SectionRequire,
+	SectionRequireIndirect,
+	SectionIgnore,
+	SectionExclude,
+	SectionReplace,
+	SectionReplaceLocal,
+	SectionTool,
+}
+
+// defaultWorkOrder is the default ordering of sections in `go.work` files.
+var defaultWorkOrder = []Section{
+	SectionHeader,
+	SectionGo,
+	SectionToolchain,
+	SectionGodebug,
+	SectionUse,
+	SectionReplace,
+	SectionReplaceLocal,
+}
+
+// CommentStyle controls how comments are formatted.
+type CommentStyle string
+
+const (
+	// CommentsNormalize trims the whitespace surrounding each comment and
+	// drops any empty comments. This is the default.
+	CommentsNormalize CommentStyle = "normalize"
+
+	// CommentsPreserve keeps each comment verbatim.
+	CommentsPreserve CommentStyle = "preserve"
+)
+
+// ConflictPolicy controls how conflicting versions of the same required
+// module are resolved.
+type ConflictPolicy string
+
+const (
+	// ConflictHighest keeps the highest semantic version of any module that is
+	// required more than once. This is the default.
+	ConflictHighest ConflictPolicy = "highest"
+
+	// ConflictError reports an error for any module that is required more
+	// than once with different versions.
+	ConflictError ConflictPolicy = "error"
+)
+
+// pinnedOrder is the order of sections which are always written first,
+// regardless of the configured order, so that header comments stay at the top
+// of the file and the `module` directive follows them.
+var pinnedOrder = []Section{
+	SectionHeader,
+	SectionModule,
+}
+
+// Options configures how `go.mod` and `go.work` files are formatted. The zero
+// value represents the default formatting used by Format.
+type Options struct {
+	// Order is the order in which sections are written. Any sections that are
+	// not listed are written afterward, in their default order. Sections that
+	// do not apply to a given kind of file are ignored. The header and module
+	// sections are always written first, and may only be listed at the start,
+	// in that order.
+	Order []Section
+
+	// MergeIndirect writes indirect dependencies in the same `require (…)`
+	// block as direct dependencies, instead of a separate block.
+	MergeIndirect bool
+
+	// MergeLocal writes local replacements in the same `replace (…)` block as
+	// module replacements, instead of a separate block.
+	MergeLocal bool
+
+	// Collapse writes blocks that contain only a single directive as a
+	// single-line directive (e.g.`require …`) instead of a block.
+	Collapse bool
+
+	// KeepGroups keeps runs of directives which are separated by blank lines
+	// within a block as distinct groups. Directives are sorted within their
+	// group, and any comments leading a group stay with that group.
+	KeepGroups bool
+
+	// Comments controls how comments are formatted. Defaults to
+	// CommentsNormalize.
+	Comments CommentStyle
+
+	// Dedupe merges duplicate `require`, `exclude`, and `tool` directives
+	// together, along with their comments. A module which is required both
+	// directly and indirectly is kept as a direct requirement.
+	Dedupe bool
+
+	// Conflicts controls how conflicting versions of the same required module
+	// are resolved when duplicates are merged. Only applies when Dedupe is
+	// set. Defaults to ConflictHighest.
+	Conflicts ConflictPolicy
+
+	// Canonical fixes module versions which the parser would otherwise
+	// accept in a non-canonical form, such as a miscased `+incompatible`
+	// suffix, or a pseudo-version with an uppercase or unshortened revision.
+	// Use Check to report these versions instead.
+	Canonical bool
+
+	// ResolveConflicts merges both sides of any git conflicts in a `go.mod`
+	// file. Conflicts may only contain `require`, `exclude`, and `replace`
+	// directives, and both sides are merged as duplicates, with conflicting
+	// versions of the same module resolved by keeping the highest version,
+	// regardless of Dedupe and Conflicts. Any directives which cannot be
+	// merged are reported as errors.
+	ResolveConflicts bool
+}
+
+// Validate returns an error if any of the given options are invalid.
+func (o Options) Validate() error {
+	for _, section := range o.Order {
+		if !slices.Contains(defaultModOrder, section) && !slices.Contains(defaultWorkOrder, section) {
+			return fmt.Errorf("unknown section %q", section)
+		}
+	}
+
+	// Pinned sections may only be listed as a leading prefix of the order,
+	// in their pinned order.
+	pinned := 0
+
+	for index, section := range o.Order {
+		position := slices.Index(pinnedOrder, section)
+		if position < 0 {
+			continue
+		}
+
+		if (index > 0 && !slices.Contains(pinnedOrder, o.Order[index-1])) || position < pinned {
+			return fmt.Errorf("section %q must be written first", section)
+		}
+
+		pinned = position + 1
+	}
+
+	switch o.Comments {
+	case "", CommentsNormalize, CommentsPreserve:
+	default:
+		return fmt.Errorf("unknown comment style %q", o.Comments)
+	}
+
+	switch o.Conflicts {
+	case "", ConflictHighest, ConflictError:
+	default:
+		return fmt.Errorf("unknown conflict policy %q", o.Conflicts)
+	}
+
+	return nil
+}
+
+// versionFixer returns the modfile.VersionFixer used when parsing files.
+func (o Options) versionFixer() modfile.VersionFixer {
+	if o.Canonical {
+		return canonicalVersion
+	}
+
+	return nil
+}
+
+// order renders the given sections according to the configured order. The
+// pinned sections are always rendered first, and any sections not configured
+// are rendered afterward in the given default order.
+// Sections are rendered in the order that they are returned, so that anything
+// written only once, such as the comments leading a group, is written in the
+// first section to be output.
+func (o Options) order(defaults []Section, sections map[Section]func() string) []string {
+	results := make([]string, 0, len(defaults))
+
+	seen := make(map[Section]bool, len(defaults))
+
+	for _, section := range slices.Concat(pinnedOrder, o.Order, defaults) {
+		if _, ok := sections[section]; !ok || seen[section] {
+			continue
+		}
+
+		seen[section] = true
+
+		results = append(results, sections[section]())
+	}
+
+	return results
+}
@@ -0,0 +1,96 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt_test
+
+import (
+	"testing"
+
+	"github.com/joshdk/modfmt/pkg/modfmt"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts modfmt.Options
+		err  string
+	}{
+		{
+			name: "zero",
+		},
+		{
+			name: "order",
+			opts: modfmt.Options{
+				Order: []modfmt.Section{modfmt.SectionRequire, modfmt.SectionGo},
+			},
+		},
+		{
+			name: "order pinned",
+			opts: modfmt.Options{
+				Order: []modfmt.Section{modfmt.SectionHeader, modfmt.SectionModule, modfmt.SectionGo},
+			},
+		},
+		{
+			name: "order module",
+			opts: modfmt.Options{
+				Order: []modfmt.Section{modfmt.SectionModule, modfmt.SectionGo},
+			},
+		},
+		{
+			name: "order unknown",
+			opts: modfmt.Options{
+				Order: []modfmt.Section{"bogus"},
+			},
+			err: `unknown section "bogus"`,
+		},
+		{
+			name: "order module later",
+			opts: modfmt.Options{
+				Order: []modfmt.Section{modfmt.SectionGo, modfmt.SectionModule},
+			},
+			err: `section "module" must be written first`,
+		},
+		{
+			name: "order header later",
+			opts: modfmt.Options{
+				Order: []modfmt.Section{modfmt.SectionModule, modfmt.SectionHeader},
+			},
+			err: `section "header" must be written first`,
+		},
+		{
+			name: "comments unknown",
+			opts: modfmt.Options{
+				Comments: "bogus",
+			},
+			err: `unknown comment style "bogus"`,
+		},
+		{
+			name: "conflicts unknown",
+			opts: modfmt.Options{
+				Conflicts: "bogus",
+			},
+			err: `unknown conflict policy "bogus"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.opts.Validate()
+
+			switch {
+			case test.err != "" && err == nil:
+				t.Fatal("expected an error")
+			case test.err != "" && err.Error() != test.err:
+				t.Fatalf("expected error %q, actual %q", test.err, err.Error())
+			case test.err == "" && err != nil:
+				t.Fatal(err)
+			}
+		})
+	}
+}
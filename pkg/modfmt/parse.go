@@ -0,0 +1,133 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package modfmt
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Kind is a kind of file that can be formatted.
+type Kind string
+
+const (
+	// KindMod is a `go.mod` file.
+	KindMod Kind = "go.mod"
+
+	// KindWork is a `go.work` file.
+	KindWork Kind = "go.work"
+)
+
+// kindOf returns the kind of file that the given file name most likely
+// refers to.
+func kindOf(file string) Kind {
+	if isWork(file) {
+		return KindWork
+	}
+
+	return KindMod
+}
+
+// ParseError is returned when data could not be parsed as either a `go.mod`
+// or `go.work` file.
+type ParseError struct {
+	// Filename is the name of the file.
+	Filename string
+
+	// Kind is the kind of file that the data was guessed to be, based on the
+	// file name, and was parsed as first.
+	Kind Kind
+
+	// Err is the error from parsing the data as the guessed kind of file.
+	Err error
+
+	// OtherErr is the error from parsing the data as the other kind of file.
+	OtherErr error
+}
+
+// Error returns the error from parsing the data as the guessed kind of file.
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the errors from both parsing attempts, starting with the
+// guessed kind of file.
+func (e *ParseError) Unwrap() []error {
+	return []error{e.Err, e.OtherErr}
+}
+
+// Positions returns the sorted positions of every problem found while parsing
+// the data as the guessed kind of file. Positions may lack a column, in which
+// case only the line is known.
+func (e *ParseError) Positions() []modfile.Position {
+	var list modfile.ErrorList
+	if !errors.As(e.Err, &list) {
+		return nil
+	}
+
+	positions := make([]modfile.Position, 0, len(list))
+	for _, err := range list {
+		positions = append(positions, err.Pos)
+	}
+
+	slices.SortStableFunc(positions, func(a, b modfile.Position) int {
+		if c := cmp.Compare(a.Line, b.Line); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.LineRune, b.LineRune)
+	})
+
+	return positions
+}
+
+// Parse attempts to parse the given data as the most likely kind of file,
+// based on the given file name, and then as the other kind of file, in the
+// same way as Format. Exactly one of the returned files is non-nil, unless a
+// *ParseError is returned.
+func Parse(file string, data []byte) (*modfile.File, *modfile.WorkFile, error) {
+	return parse(file, data, nil)
+}
+
+// parse attempts to parse the given data as the most likely kind of file,
+// based on the given file name, and then as the other kind of file. Exactly
+// one of the returned files is non-nil, unless a *ParseError is returned.
+func parse(file string, data []byte, fix modfile.VersionFixer) (*modfile.File, *modfile.WorkFile, error) {
+	parseErr := &ParseError{Filename: file, Kind: kindOf(file)}
+
+	if parseErr.Kind == KindWork {
+		work, err := modfile.ParseWork(file, data, fix)
+		if err == nil {
+			return nil, work, nil
+		}
+
+		mod, otherErr := modfile.Parse(file, data, fix)
+		if otherErr == nil {
+			return mod, nil, nil
+		}
+
+		parseErr.Err, parseErr.OtherErr = err, otherErr
+
+		return nil, nil, parseErr
+	}
+
+	mod, err := modfile.Parse(file, data, fix)
+	if err == nil {
+		return mod, nil, nil
+	}
+
+	work, otherErr := modfile.ParseWork(file, data, fix)
+	if otherErr == nil {
+		return nil, work, nil
+	}
+
+	parseErr.Err, parseErr.OtherErr = err, otherErr
+
+	return nil, nil, parseErr
+}
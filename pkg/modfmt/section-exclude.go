@@ -15,17 +15,18 @@ import (
 // an empty string if the section contains no directives.
 //
 // See https://go.dev/ref/mod#go-mod-file-exclude
-func sectionExclude(directives []*modfile.Exclude) string {
+func (f *formatter) sectionExclude(directives []*modfile.Exclude) string {
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
 		i := item{
-			comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+			comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
 			line:     fmt.Sprintf("%s %s", directive.Mod.Path, directive.Mod.Version),
+			syntax:   directive.Syntax,
 		}
 
 		items = append(items, i)
 	}
 
-	return block("exclude", items)
+	return f.block("exclude", items)
 }
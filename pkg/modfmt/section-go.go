@@ -14,15 +14,15 @@ import (
 //
 // https://go.dev/ref/mod#go-mod-file-go
 // https://go.dev/ref/mod#go-work-file-go
-func sectionGo(directive *modfile.Go) string {
+func (f *formatter) sectionGo(directive *modfile.Go) string {
 	if directive == nil {
 		return ""
 	}
 
 	i := item{
-		comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+		comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
 		line:     directive.Version,
 	}
 
-	return value("go", i)
+	return f.value("go", i)
 }
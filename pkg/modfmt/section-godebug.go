@@ -16,17 +16,18 @@ import (
 //
 // See https://go.dev/ref/mod#go-mod-file-godebug
 // See https://go.dev/ref/mod#go-work-file-godebug
-func sectionGodebug(directives []*modfile.Godebug) string {
+func (f *formatter) sectionGodebug(directives []*modfile.Godebug) string {
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
 		i := item{
-			comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+			comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
 			line:     fmt.Sprintf("%s=%s", directive.Key, directive.Value),
+			syntax:   directive.Syntax,
 		}
 
 		items = append(items, i)
 	}
 
-	return block("godebug", items)
+	return f.block("godebug", items)
 }
@@ -14,12 +14,12 @@ import (
 //
 // See https://go.dev/ref/mod#go-mod-file-go
 // See https://go.dev/ref/mod#go-work-file-go
-func sectionHeader(file *modfile.FileSyntax) string {
+func (f *formatter) sectionHeader(file *modfile.FileSyntax) string {
 	var lines []string
 
 	for _, statement := range file.Stmt {
 		if commentBlock, ok := statement.(*modfile.CommentBlock); ok {
-			lines = append(lines, extractComments(commentBlock.Before)...)
+			lines = append(lines, f.extractComments(commentBlock.Before)...)
 		}
 	}
 
@@ -13,17 +13,18 @@ import (
 // an empty string if the section contains no directives.
 //
 // See https://go.dev/ref/mod#go-mod-file-ignore
-func sectionIgnore(directives []*modfile.Ignore) string {
+func (f *formatter) sectionIgnore(directives []*modfile.Ignore) string {
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
 		i := item{
-			comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+			comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
 			line:     directive.Path,
+			syntax:   directive.Syntax,
 		}
 
 		items = append(items, i)
 	}
 
-	return block("ignore", items)
+	return f.block("ignore", items)
 }
@@ -6,22 +6,108 @@
 package modfmt
 
 import (
+	"slices"
+	"strings"
+
 	"golang.org/x/mod/modfile"
 )
 
+// deprecatedPrefix starts the paragraph of comments which deprecates a module.
+const deprecatedPrefix = "Deprecated:"
+
 // sectionModule formats the `module …` section for `go.mod` files. Returns an
-// empty string if the section directive has no value.
+// empty string if the section directive has no value. Any deprecation comment
+// is written in the canonical `// Deprecated: …` form, as its own paragraph
+// immediately above the directive.
 //
 // See https://go.dev/ref/mod#go-mod-file-module
-func sectionModule(directive *modfile.Module) string {
+// See https://go.dev/ref/mod#go-mod-file-module-deprecation
+func (f *formatter) sectionModule(directive *modfile.Module) string {
 	if directive == nil {
 		return ""
 	}
 
+	comments := slices.Concat(directive.Syntax.Before, directive.Syntax.Suffix)
+
+	if directive.Deprecated != "" {
+		comments = withoutDeprecation(comments)
+	}
+
 	i := item{
-		comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+		comments: f.extractComments(comments),
 		line:     directive.Mod.Path,
 	}
 
-	return value("module", i)
+	if directive.Deprecated != "" {
+		// The deprecation must be a separate paragraph in order to be
+		// recognized.
+		if len(i.comments) > 0 {
+			i.comments = append(i.comments, "//")
+		}
+
+		for index, line := range strings.Split(directive.Deprecated, "\n") {
+			if index == 0 {
+				line = deprecatedPrefix + " " + line
+			}
+
+			i.comments = append(i.comments, "// "+line)
+		}
+	}
+
+	return f.value("module", i)
+}
+
+// withoutDeprecation returns the given comments without the first paragraph
+// which starts with "Deprecated:", along with the empty comment separating
+// it from the other paragraphs. Paragraphs are separated by empty comments,
+// and blank lines are ignored, in the same way as modfile.Module.Deprecated.
+func withoutDeprecation(comments []modfile.Comment) []modfile.Comment {
+	text := func(comment modfile.Comment) string {
+		return strings.TrimSpace(strings.TrimPrefix(comment.Token, "//"))
+	}
+
+	separator := func(comment modfile.Comment) bool {
+		return strings.HasPrefix(comment.Token, "//") && text(comment) == ""
+	}
+
+	start, end := -1, len(comments)
+	paragraph := true
+
+	for index, comment := range comments {
+		if isBlank(comment) {
+			continue
+		}
+
+		if separator(comment) {
+			if start >= 0 {
+				end = index
+
+				break
+			}
+
+			paragraph = true
+
+			continue
+		}
+
+		if paragraph && start < 0 && strings.HasPrefix(text(comment), deprecatedPrefix) {
+			start = index
+		}
+
+		paragraph = false
+	}
+
+	if start < 0 {
+		return comments
+	}
+
+	// Drop the separator after the paragraph, or otherwise the one before it.
+	switch {
+	case end < len(comments):
+		end++
+	case start > 0 && separator(comments[start-1]):
+		start--
+	}
+
+	return slices.Concat(comments[:start], comments[end:])
 }
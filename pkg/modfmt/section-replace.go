@@ -21,25 +21,34 @@ func isLocal(name string) bool {
 // another package. Returns an empty string if the section contains no
 // directives.
 //
+// If local replacements are being merged, then replace directives where a
+// package is being replaced by a local file path are also included.
+//
 // See https://go.dev/ref/mod#go-mod-file-replace
 // See https://go.dev/ref/mod#go-work-file-replace
-func sectionReplace(directives []*modfile.Replace) string {
+func (f *formatter) sectionReplace(directives []*modfile.Replace) string {
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
-		if isLocal(directive.New.Path) {
+		local := isLocal(directive.New.Path)
+		if local && !f.opts.MergeLocal {
 			continue
 		}
 
 		i := item{
-			comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+			comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
 			line:     stringReplace(directive),
+			syntax:   directive.Syntax,
+		}
+
+		if local {
+			i.line = stringReplaceLocal(directive)
 		}
 
 		items = append(items, i)
 	}
 
-	return block("replace", items)
+	return f.block("replace", items)
 }
 
 func stringReplace(directive *modfile.Replace) string {
@@ -54,11 +63,15 @@ func stringReplace(directive *modfile.Replace) string {
 // sectionReplaceLocal formats the `replace (…)` section for `go.mod` and
 // `go.work` files. Only includes replace directives where a package is being
 // replaced by a local file path. Returns an empty string if the section
-// contains no directives.
+// contains no directives, or if local replacements are being merged.
 //
 // See https://go.dev/ref/mod#go-mod-file-replace
 // See https://go.dev/ref/mod#go-work-file-replace
-func sectionReplaceLocal(directives []*modfile.Replace) string {
+func (f *formatter) sectionReplaceLocal(directives []*modfile.Replace) string {
+	if f.opts.MergeLocal {
+		return ""
+	}
+
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
@@ -67,14 +80,15 @@ func sectionReplaceLocal(directives []*modfile.Replace) string {
 		}
 
 		i := item{
-			comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+			comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
 			line:     stringReplaceLocal(directive),
+			syntax:   directive.Syntax,
 		}
 
 		items = append(items, i)
 	}
 
-	return block("replace", items)
+	return f.block("replace", items)
 }
 
 func stringReplaceLocal(directive *modfile.Replace) string {
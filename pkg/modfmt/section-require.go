@@ -7,40 +7,42 @@ package modfmt
 
 import (
 	"fmt"
+	"strings"
 
 	"golang.org/x/mod/modfile"
 )
 
 // sectionRequire formats the `require (…)` section for `go.mod` files. Only
-// includes require directives where a package is required directly. Returns an
-// empty string if the section contains no directives.
+// includes require directives where a package is required directly, unless
+// indirect requirements are being merged. Returns an empty string if the
+// section contains no directives.
 //
 // See https://go.dev/ref/mod#go-mod-file-require
-func sectionRequire(directives []*modfile.Require) string {
+func (f *formatter) sectionRequire(directives []*modfile.Require) string {
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
-		if directive.Indirect {
+		if directive.Indirect && !f.opts.MergeIndirect {
 			continue
 		}
 
-		i := item{
-			comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
-			line:     fmt.Sprintf("%s %s", directive.Mod.Path, directive.Mod.Version),
-		}
-
-		items = append(items, i)
+		items = append(items, f.itemRequire(directive))
 	}
 
-	return block("require", items)
+	return f.block("require", items)
 }
 
 // sectionRequireIndirect formats the `require (…)` section for `go.mod` files.
-// Only includes require directives where a package is indirectly directly.
-// Returns an empty string if the section contains no directives.
+// Only includes require directives where a package is required indirectly.
+// Returns an empty string if the section contains no directives, or if
+// indirect requirements are being merged.
 //
 // See https://go.dev/ref/mod#go-mod-file-require
-func sectionRequireIndirect(directives []*modfile.Require) string {
+func (f *formatter) sectionRequireIndirect(directives []*modfile.Require) string {
+	if f.opts.MergeIndirect {
+		return ""
+	}
+
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
@@ -48,13 +50,41 @@ func sectionRequireIndirect(directives []*modfile.Require) string {
 			continue
 		}
 
-		i := item{
-			comments: extractComments(directive.Syntax.Before),
-			line:     fmt.Sprintf("%s %s // indirect", directive.Mod.Path, directive.Mod.Version),
+		items = append(items, f.itemRequire(directive))
+	}
+
+	return f.block("require", items)
+}
+
+func (f *formatter) itemRequire(directive *modfile.Require) item {
+	if directive.Indirect {
+		// The first suffix comment contains the `// indirect` marker, along
+		// with any other text that must be kept.
+		line := fmt.Sprintf("%s %s // indirect", directive.Mod.Path, directive.Mod.Version)
+		if rest := indirectRemainder(directive.Syntax.Suffix[0].Token); rest != "" {
+			line += "; " + rest
+		}
+
+		return item{
+			comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix[1:]),
+			line:     line,
+			syntax:   directive.Syntax,
 		}
+	}
 
-		items = append(items, i)
+	return item{
+		comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+		line:     fmt.Sprintf("%s %s", directive.Mod.Path, directive.Mod.Version),
+		syntax:   directive.Syntax,
 	}
+}
+
+// indirectRemainder returns any text that follows the `indirect` marker in the
+// given comment token (e.g.`// indirect; pinned for …`), with surrounding
+// whitespace and separators removed.
+func indirectRemainder(token string) string {
+	text := strings.TrimSpace(strings.TrimPrefix(token, "//"))
+	text = strings.TrimPrefix(text, "indirect")
 
-	return block("require", items)
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), ";"))
 }
@@ -7,27 +7,31 @@ package modfmt
 
 import (
 	"fmt"
+	"strings"
 
 	"golang.org/x/mod/modfile"
 )
 
 // sectionRetract formats the `retract (…)` section for `go.mod` files. Returns
-// an empty string if the section contains no directives.
+// an empty string if the section contains no directives. The comments on each
+// retraction are its rationale, and are written so that the rationale is
+// unchanged.
 //
 // See https://go.dev/ref/mod#go-mod-file-retract
-func sectionRetract(directives []*modfile.Retract) string {
+func (f *formatter) sectionRetract(directives []*modfile.Retract) string {
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
 		i := item{
-			comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+			comments: f.rationaleComments(directive),
 			line:     stringRetract(directive),
+			syntax:   directive.Syntax,
 		}
 
 		items = append(items, i)
 	}
 
-	return block("retract", items)
+	return f.block("retract", items)
 }
 
 func stringRetract(directive *modfile.Retract) string {
@@ -38,3 +42,29 @@ func stringRetract(directive *modfile.Retract) string {
 		return directive.Low
 	}
 }
+
+// rationaleComments returns the comment lines to write above the given
+// retraction, so that its rationale is read back exactly as
+// modfile.Retract.Rationale reports it. The rationale includes the comments of
+// the enclosing block when the retraction has none of its own, and empty
+// comments separate its paragraphs, so it is written out in full rather than
+// normalizing the comments on the line. Comments on the line are kept as-is
+// if comments are being preserved.
+func (f *formatter) rationaleComments(directive *modfile.Retract) []string {
+	if f.opts.Comments == CommentsPreserve {
+		if lines := f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix); len(lines) > 0 {
+			return lines
+		}
+	}
+
+	if directive.Rationale == "" {
+		return nil
+	}
+
+	lines := strings.Split(directive.Rationale, "\n")
+	for index, line := range lines {
+		lines[index] = strings.TrimSpace("// " + line)
+	}
+
+	return lines
+}
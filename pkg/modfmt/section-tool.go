@@ -13,17 +13,18 @@ import (
 // empty string if the section contains no directives.
 //
 // See https://go.dev/ref/mod#go-mod-file-tool
-func sectionTool(directives []*modfile.Tool) string {
+func (f *formatter) sectionTool(directives []*modfile.Tool) string {
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
 		i := item{
-			comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+			comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
 			line:     directive.Path,
+			syntax:   directive.Syntax,
 		}
 
 		items = append(items, i)
 	}
 
-	return block("tool", items)
+	return f.block("tool", items)
 }
@@ -15,15 +15,15 @@ import (
 //
 // See https://go.dev/ref/mod#go-mod-file-toolchain
 // See https://go.dev/ref/mod#go-work-file-toolchain
-func sectionToolchain(directive *modfile.Toolchain) string {
+func (f *formatter) sectionToolchain(directive *modfile.Toolchain) string {
 	if directive == nil {
 		return ""
 	}
 
 	i := item{
-		comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+		comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
 		line:     directive.Name,
 	}
 
-	return value("toolchain", i)
+	return f.value("toolchain", i)
 }
@@ -13,17 +13,18 @@ import (
 // empty string if the section contains no directives.
 //
 // See https://go.dev/ref/mod#go-work-file-use
-func sectionUse(directives []*modfile.Use) string {
+func (f *formatter) sectionUse(directives []*modfile.Use) string {
 	items := make([]item, 0, len(directives))
 
 	for _, directive := range directives {
 		i := item{
-			comments: extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
+			comments: f.extractComments(directive.Syntax.Before, directive.Syntax.Suffix),
 			line:     directive.Path,
+			syntax:   directive.Syntax,
 		}
 
 		items = append(items, i)
 	}
 
-	return block("use", items)
+	return f.block("use", items)
 }
@@ -6,27 +6,144 @@
 package modfmt
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"strings"
 
 	"golang.org/x/mod/modfile"
 )
 
+// formatter formats the individual sections of `go.mod` and `go.work` files
+// according to a set of options.
+type formatter struct {
+	// opts are the options used when formatting sections.
+	opts Options
+
+	// groups holds the group that each line within a block belongs to. Only
+	// populated when groups are being kept.
+	groups map[*modfile.Line]*group
+}
+
+// group represents a run of lines within a block, separated from other runs
+// by a blank line.
+type group struct {
+	// index is the position of this group relative to all other groups in
+	// the file.
+	index int
+
+	// comments is an optional set of comment lines (including the comment
+	// prefix) which lead the group.
+	comments []string
+
+	// written is true once the comments have been written. A group may be
+	// split across multiple sections, such as when it mixes direct and
+	// indirect requirements, and its comments are only written in the first.
+	written bool
+}
+
+// newFormatter returns a formatter for the given file syntax. If groups are
+// being kept, then each line within a block that contains blank lines is
+// assigned to a group, and the leading comments of each group are detached
+// from the first line in that group.
+func newFormatter(syntax *modfile.FileSyntax, opts Options) *formatter {
+	f := &formatter{opts: opts}
+	if !opts.KeepGroups {
+		return f
+	}
+
+	f.groups = make(map[*modfile.Line]*group)
+
+	var index int
+
+	for _, statement := range syntax.Stmt {
+		block, ok := statement.(*modfile.LineBlock)
+		if !ok {
+			continue
+		}
+
+		// Split the lines of the block into runs, where a blank line
+		// (represented by an empty comment) starts a new run.
+		var runs [][]*modfile.Line
+
+		for i, line := range block.Line {
+			if i == 0 || slices.ContainsFunc(line.Before, isBlank) {
+				runs = append(runs, nil)
+			}
+
+			runs[len(runs)-1] = append(runs[len(runs)-1], line)
+		}
+
+		// A block without any blank lines has nothing to keep apart, so its
+		// lines are left ungrouped, and sorted together with the lines from
+		// any other such blocks.
+		if len(runs) == 1 {
+			continue
+		}
+
+		for _, run := range runs {
+			index++
+
+			g := &group{index: index}
+
+			// The leading comments of a retraction are its rationale, and so
+			// stay with the line.
+			if block.Token[0] != "retract" {
+				g.comments = f.extractComments(run[0].Before)
+				run[0].Before = nil
+			}
+
+			for _, line := range run {
+				f.groups[line] = g
+			}
+		}
+	}
+
+	return f
+}
+
+// compareGroups compares the groups of the given lines, so that lines are
+// only ever sorted within their own group. Lines that do not belong to a group
+// are sorted before all other lines.
+func (f *formatter) compareGroups(a, b *modfile.Line) int {
+	var indexA, indexB int
+
+	if g, ok := f.groups[a]; ok {
+		indexA = g.index
+	}
+
+	if g, ok := f.groups[b]; ok {
+		indexB = g.index
+	}
+
+	return cmp.Compare(indexA, indexB)
+}
+
+// isBlank reports if the given comment represents a blank line.
+func isBlank(comment modfile.Comment) bool {
+	return comment.Token == ""
+}
+
 // item represents a single entry to be used either alone in a value directive
 // or as a collection in a block directive.
 type item struct {
-	// comments is an optional set of comments to include with this entry.
+	// comments is an optional set of comment lines (including the comment
+	// prefix) to include with this entry.
 	comments []string
 
 	// line is a (potentially formatted) string value for this entry.
 	line string
+
+	// syntax is the original line for this entry, if it came from a block
+	// directive.
+	syntax *modfile.Line
 }
 
-// comments formats the given lines as comments with an optional indent prefix.
+// comments formats the given comment lines with an optional indent prefix.
 func comments(lines []string, indent string) string {
 	var result string
 	for _, line := range lines {
-		result += fmt.Sprintf("%s// %s\n", indent, line)
+		result += fmt.Sprintf("%s%s\n", indent, line)
 	}
 
 	return result
@@ -34,7 +151,7 @@ func comments(lines []string, indent string) string {
 
 // value formats a single value directive (e.g.`module …`). Returns an empty string
 // if the given item has an empty line.
-func value(name string, i item) string {
+func (f *formatter) value(name string, i item) string {
 	if i.line == "" {
 		return ""
 	}
@@ -46,14 +163,43 @@ func value(name string, i item) string {
 }
 
 // block formats a single block directive (e.g.`require (…)`). Returns an empty
-// string if the given item slice is empty.
-func block(name string, items []item) string {
-	if len(items) == 0 {
+// string if the given item slice is empty. If single-entry blocks are being
+// collapsed, then a block containing a single item is instead formatted as a
+// value directive (e.g.`require …`).
+func (f *formatter) block(name string, items []item) string {
+	switch {
+	case len(items) == 0:
 		return ""
+	case len(items) == 1 && f.opts.Collapse:
+		// The comments which lead the group are written above the directive.
+		if current := f.groups[items[0].syntax]; current != nil && !current.written {
+			items[0].comments = slices.Concat(current.comments, items[0].comments)
+			current.written = true
+		}
+
+		return f.value(name, items[0])
 	}
 
 	result := name + " (\n"
-	for _, i := range items {
+
+	var previous *group
+
+	for index, i := range items {
+		// Separate each group with a blank line, and write the comments which
+		// lead the group.
+		if current := f.groups[i.syntax]; current != previous {
+			if index > 0 {
+				result += "\n"
+			}
+
+			if current != nil && !current.written {
+				result += comments(current.comments, "\t")
+				current.written = true
+			}
+
+			previous = current
+		}
+
 		result += comments(i.comments, "\t")
 		result += fmt.Sprintf("\t%s\n", i.line)
 	}
@@ -63,16 +209,27 @@ func block(name string, items []item) string {
 	return result
 }
 
-// extractComments extracts, simplifies, and combines comment lines from the
-// given modfile.Comment inputs. Returned lines will be stripped of the comment
-// prefix (`//`).
-func extractComments(sections ...[]modfile.Comment) []string {
+// extractComments extracts and combines comment lines from the given
+// modfile.Comment inputs. Comment lines are simplified and empty comments are
+// dropped, unless comments are being preserved.
+func (f *formatter) extractComments(sections ...[]modfile.Comment) []string {
 	var lines []string
 
 	for _, section := range sections {
 		for _, comment := range section {
+			if isBlank(comment) {
+				// Blank lines are never included as comments.
+				continue
+			}
+
+			if f.opts.Comments == CommentsPreserve {
+				lines = append(lines, comment.Token)
+
+				continue
+			}
+
 			if line := strings.TrimSpace(strings.TrimPrefix(comment.Token, "//")); line != "" {
-				lines = append(lines, line)
+				lines = append(lines, "// "+line)
 			}
 		}
 	}
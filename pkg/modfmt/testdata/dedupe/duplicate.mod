@@ -0,0 +1,27 @@
+module example.com/foo/bar
+
+go 1.24.0
+
+require (
+	// first a
+	example.com/a/a v1.1.1
+	example.com/b/b v1.2.0 // indirect; pinned
+	example.com/c/c v1.0.0 // indirect
+)
+
+require (
+	// second a
+	example.com/a/a v1.1.1 // same
+	example.com/b/b v1.10.0
+	example.com/c/c v1.0.0 // indirect
+)
+
+exclude example.com/d/d v1.0.0 // bad release
+exclude example.com/d/d v1.0.0 // bad release
+exclude example.com/d/d v1.0.1
+
+tool example.com/e/e/cmd/e
+tool (
+	// also here
+	example.com/e/e/cmd/e
+)
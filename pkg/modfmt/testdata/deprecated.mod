@@ -0,0 +1,11 @@
+// Header comment.
+
+// Use example.com/foo/baz instead.
+//
+//   Deprecated:    Use example.com/foo/baz,
+//   which is faster.
+//
+// Another comment.
+module example.com/foo/bar // Suffix comment.
+
+go 1.23.0
@@ -0,0 +1,13 @@
+module example.com/foo/bar
+
+go 1.23.0
+
+require (
+	// internal libs
+	example.com/z/z v1.0.0 // indirect
+	example.com/a/a v1.0.0
+
+	// third-party
+	github.com/y/y v1.0.0 // indirect
+	github.com/b/b v1.0.0 // indirect
+)
@@ -0,0 +1,19 @@
+module example.com/foo/bar
+
+go 1.23.0
+
+require (
+	example.com/b/b v1.0.0
+)
+
+require (
+	example.com/a/a v1.0.0
+)
+
+require (
+	example.com/c/c v1.0.0
+	example.com/d/d v1.0.0
+
+	// third-party
+	github.com/b/b v1.0.0
+)
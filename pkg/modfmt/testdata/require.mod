@@ -6,3 +6,4 @@ require example.com/a/a v1.1.1
 require example.com/b/b v1.2.2 // indirect
 
 require example.com/c/c v1.3.3
+require example.com/d/d v1.4.4 // indirect;   pinned for CVE-2024-1234
@@ -0,0 +1,16 @@
+module example.com/foo/bar
+
+go 1.23.0
+
+// Published accidentally.
+retract (
+	v1.0.0
+	v1.1.0 //   Contains a security vulnerability.
+
+	// First paragraph.
+	//
+	// Second paragraph.
+	[v1.2.0, v1.2.3]
+)
+
+retract v0.1.0 // Too old.